@@ -3,41 +3,62 @@ package main
 import (
 	"context"
 	"flag"
-	"net/http"
+	"fmt"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"routers"
 	"setting"
+	"strings"
 	"time"
 
+	"modules/errors"
+	"modules/pagination"
+	"modules/responser"
 	"modules/validator"
 	"modules/zerolog"
 
-	"github.com/gocommon/rotatefile"
 	"github.com/labstack/echo/middleware"
 
 	"github.com/labstack/echo"
 )
 
-var configPath string
+var (
+	configPath    string
+	configSets    setting.SetFlags
+	configProfile string
+)
 
 func init() {
 	pwd, _ := os.Getwd()
-	flag.StringVar(&configPath, "c", filepath.Join(pwd, "./src/cmd/main/app.toml"), "-c /path/to/app.toml config gile")
+	flag.StringVar(&configPath, "c", filepath.Join(pwd, "./src/cmd/main/app.toml"), "-c /path/to/app.toml,/path/to/prod.yaml config files, merged in order")
+	flag.StringVar(&configProfile, "profile", "", "-profile dev|test|staging|prod, default $APP_ENV or prod")
+	flag.Var(&configSets, "set", "-set Echo.Listen=:9000 override config, repeatable")
 }
 
 // 一些初始化工作
 func bootstrap() {
-	if err := setting.InitConf(configPath); err != nil {
-		panic(err)
+	// 配置有误时输出所有问题后退出
+	if err := setting.InitConf(configProfile, strings.Split(configPath, ","), configSets...); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 	// 版本号
 	setting.Conf.Version = VER
 
-	zerolog.InitLog(setting.Conf.ZeroLogs, zerolog.Timestamp(), zerolog.Version(setting.Conf.Version))
+	// 日志及脱敏
+	initLog(setting.Conf)
 
-	zerolog.Debug().Interface("conf", setting.Conf).Go()
+	zerolog.Debug().Interface("conf", zerolog.Redact(setting.Conf)).Go()
+	for _, src := range setting.Sources() {
+		if src.From != setting.SourceDefault {
+			zerolog.Debug().Str("key", src.Key).Interface("value", src.Value).Str("from", src.From).Msg("config source")
+		}
+	}
+
+	// 返回值格式
+	responser.Init(setting.Conf.Responser)
+	pagination.Init(setting.Conf.Pagination)
 }
 
 func main() {
@@ -55,71 +76,40 @@ func main() {
 	///////////////// 中间件 ////////////////
 	///									////
 
-	// 访问日志，写文件
-	if setting.Conf.Echo.AccessLog {
-		loggerConfig := middleware.DefaultLoggerConfig
-
-		if setting.Conf.Echo.AccessLogFile {
-			f, err := rotatefile.NewWriter(rotatefile.Options{Filename: setting.Conf.Echo.AccessLogFilePath})
-			defer f.Close()
-			loggerConfig.Output = f
-			if err != nil {
-				panic(err)
-			}
-		}
+	// 访问日志、请求体记录、跨域在重新加载配置时替换
+	accessLog := newSwapMiddleware("access_log", accessLogMiddleware(setting.Conf))
+	bodyDump := newSwapMiddleware("body_dump", bodyDumpMiddleware(setting.Conf))
+	cors := newSwapMiddleware("cors", corsMiddleware(setting.Conf))
+	subscribeReload(accessLog, bodyDump, cors)
 
-		e.Use(middleware.LoggerWithConfig(loggerConfig))
-	}
+	// 访问日志，输出方式在 ZeroLogs 中配置
+	e.Use(accessLog.middleware)
 
 	e.Use(middleware.Recover())
 
-	if setting.Conf.Echo.CrosEnable {
-		e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-			AllowOrigins: setting.Conf.Echo.CrosAllowOrigins,
-			AllowMethods: []string{echo.GET, echo.PUT, echo.POST, echo.DELETE},
-		}))
-	}
+	// 请求日志，handler 中通过 zerolog.FromEcho(c) 获取
+	e.Use(zerolog.Middleware())
+
+	e.Use(cors.middleware)
 
 	if setting.Conf.Echo.GzipEnable {
-		e.Use(middleware.Gzip())
+		e.Use(middleware.GzipWithConfig(middleware.GzipConfig{
+			// SSE 不压缩
+			Skipper: responser.StreamGzipSkipper,
+		}))
 	}
 
+	// 在 Gzip 之后，记录压缩前的返回值
+	e.Use(bodyDump.middleware)
+
 	////								////
 	///////////////// 中间件 ////////////////
 
 	// 统计错误处理
-	e.HTTPErrorHandler = func(err error, c echo.Context) {
-		var (
-			code = http.StatusInternalServerError
-			msg  interface{}
-		)
-
-		if he, ok := err.(*echo.HTTPError); ok {
-			code = he.Code
-			msg = he.Message
-		} else if e.Debug {
-			msg = err.Error()
-		} else {
-			msg = http.StatusText(code)
-		}
-		if _, ok := msg.(string); ok {
-			msg = echo.Map{"message": msg}
-		}
-
-		if !c.Response().Committed {
-			if c.Request().Method == echo.HEAD { // Issue #608
-				if err := c.NoContent(code); err != nil {
-					goto ERROR
-				}
-			} else {
-				if err := c.JSON(code, msg); err != nil {
-					goto ERROR
-				}
-			}
-		}
-	ERROR:
-		e.Logger.Error(err)
-	}
+	e.HTTPErrorHandler = errors.HTTPErrorHandlerWithConfig(errors.Config{
+		Debug:  e.Debug,
+		Logger: "default",
+	})
 
 	// 注册路由
 	routers.InitRouters(e)
@@ -133,9 +123,13 @@ func main() {
 		}
 	}()
 
+	// SIGHUP 及配置文件变化时重新加载配置
+	stopReload := watchReload()
+	defer stopReload()
+
 	// Wait for interrupt signal to gracefully shutdown the server with
 	// a timeout of 10 seconds.
-	quit := make(chan os.Signal)
+	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt)
 	<-quit
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -143,4 +137,7 @@ func main() {
 	if err := e.Shutdown(ctx); err != nil {
 		zerolog.Error().Err(err).Msg("echo Shutdown err")
 	}
+
+	// 发送未发出的邮件日志
+	zerolog.Close()
 }
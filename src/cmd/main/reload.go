@@ -0,0 +1,147 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"setting"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"modules/zerolog"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+)
+
+// swapMiddleware 可替换的中间件，重新加载配置时替换，nil 时直接调用下一个
+// 替换时生成一次处理函数，请求中的下一个处理函数通过 c.Set(key) 传入
+type swapMiddleware struct {
+	key string
+	v   atomic.Value // middlewareBox
+}
+
+type middlewareBox struct {
+	h echo.HandlerFunc
+}
+
+func newSwapMiddleware(key string, m echo.MiddlewareFunc) *swapMiddleware {
+	s := &swapMiddleware{key: "_swap_" + key}
+	s.swap(m)
+	return s
+}
+
+func (s *swapMiddleware) swap(m echo.MiddlewareFunc) {
+	var h echo.HandlerFunc
+	if m != nil {
+		h = m(s.next)
+	}
+	s.v.Store(middlewareBox{h})
+}
+
+// next 调用请求中保存的下一个处理函数
+func (s *swapMiddleware) next(c echo.Context) error {
+	return c.Get(s.key).(echo.HandlerFunc)(c)
+}
+
+// middleware echo.MiddlewareFunc
+func (s *swapMiddleware) middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		h := s.v.Load().(middlewareBox).h
+		if h == nil {
+			return next(c)
+		}
+		c.Set(s.key, next)
+		return h(c)
+	}
+}
+
+// accessLogMiddleware 访问日志，输出方式在 ZeroLogs 中配置
+func accessLogMiddleware(conf *setting.Config) echo.MiddlewareFunc {
+	if !conf.Echo.AccessLog {
+		return nil
+	}
+	return zerolog.AccessLogWithConfig(zerolog.AccessLogConfig{
+		Logger:        conf.Echo.AccessLogger,
+		SampleRate:    conf.Echo.AccessLogSampleRate,
+		SlowThreshold: time.Duration(conf.Echo.AccessLogSlowMillis) * time.Millisecond,
+		Headers:       conf.Echo.AccessLogHeaders,
+	})
+}
+
+func bodyDumpMiddleware(conf *setting.Config) echo.MiddlewareFunc {
+	if !conf.Echo.BodyDump {
+		return nil
+	}
+	return zerolog.BodyDump()
+}
+
+func corsMiddleware(conf *setting.Config) echo.MiddlewareFunc {
+	if !conf.Echo.CrosEnable {
+		return nil
+	}
+	return middleware.CORSWithConfig(middleware.CORSConfig{
+		AllowOrigins: conf.Echo.CrosAllowOrigins,
+		AllowMethods: []string{echo.GET, echo.PUT, echo.POST, echo.DELETE},
+	})
+}
+
+// initLog 日志及脱敏，启动和重新加载时调用
+// InitLog 失败时 panic，此时还没有替换输出和脱敏配置
+func initLog(conf *setting.Config) {
+	zerolog.InitLog(conf.ZeroLogs, zerolog.Timestamp(), zerolog.Version(conf.Version))
+	zerolog.InitRedact(conf.Redact)
+}
+
+// subscribeReload 重新加载配置时重新应用的部分，其它配置项需要重启
+func subscribeReload(accessLog, bodyDump, cors *swapMiddleware) {
+	// Echo.AccessLogFile 等生成的访问日志配置也在 ZeroLogs 中
+	setting.Subscribe(func(old, new *setting.Config) error {
+		initLog(new)
+		return nil
+	}, "ZeroLogs", "Redact")
+
+	setting.Subscribe(func(old, new *setting.Config) error {
+		accessLog.swap(accessLogMiddleware(new))
+		return nil
+	}, "Echo.AccessLog")
+
+	setting.Subscribe(func(old, new *setting.Config) error {
+		bodyDump.swap(bodyDumpMiddleware(new))
+		return nil
+	}, "Echo.BodyDump")
+
+	setting.Subscribe(func(old, new *setting.Config) error {
+		cors.swap(corsMiddleware(new))
+		return nil
+	}, "Echo.Cros")
+}
+
+// watchReload SIGHUP 及配置文件变化时重新加载，返回停止函数
+func watchReload() (stop func()) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-hup:
+				setting.Reload()
+			}
+		}
+	}()
+
+	stopWatch := func() {}
+	if conf := setting.Get(); conf.Reload.Watch {
+		stopWatch = setting.Watch(time.Duration(conf.Reload.WatchSeconds) * time.Second)
+	}
+
+	return func() {
+		signal.Stop(hup)
+		close(done)
+		stopWatch()
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo"
+)
+
+func TestSwapMiddlewareBuildsOnce(t *testing.T) {
+	built := 0
+	header := func(v string) echo.MiddlewareFunc {
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			built++
+			return func(c echo.Context) error {
+				c.Response().Header().Set("X-Swap", v)
+				return next(c)
+			}
+		}
+	}
+
+	s := newSwapMiddleware("test", header("a"))
+
+	e := echo.New()
+	e.Use(s.middleware)
+	e.GET("/a", func(c echo.Context) error { return c.String(http.StatusOK, "a") })
+	e.GET("/b", func(c echo.Context) error { return c.String(http.StatusOK, "b") })
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(echo.GET, path, nil))
+		return rec
+	}
+
+	for i := 0; i < 3; i++ {
+		for _, path := range []string{"/a", "/b"} {
+			rec := get(path)
+			if rec.Body.String() != path[1:] || rec.Header().Get("X-Swap") != "a" {
+				t.Fatalf("%s: body %q header %q", path, rec.Body, rec.Header().Get("X-Swap"))
+			}
+		}
+	}
+	if built != 1 {
+		t.Fatalf("built %d times", built)
+	}
+
+	s.swap(header("b"))
+	if rec := get("/a"); rec.Header().Get("X-Swap") != "b" || built != 2 {
+		t.Fatalf("after swap: header %q built %d", rec.Header().Get("X-Swap"), built)
+	}
+
+	s.swap(nil)
+	if rec := get("/b"); rec.Body.String() != "b" || len(rec.Header().Get("X-Swap")) > 0 {
+		t.Fatalf("after disable: body %q header %q", rec.Body, rec.Header().Get("X-Swap"))
+	}
+}
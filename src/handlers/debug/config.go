@@ -0,0 +1,16 @@
+package debug
+
+import (
+	"net/http"
+	"setting"
+
+	"modules/responser"
+
+	"github.com/labstack/echo"
+)
+
+// Config 所有配置项的生效值及来源(default, file, env, flag)，敏感值脱敏
+// 只能本机或带 X-Debug-Token 访问，见 setting.DebugOption
+func Config(c echo.Context) error {
+	return responser.R(c, http.StatusOK, setting.Sources())
+}
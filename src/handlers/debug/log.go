@@ -0,0 +1,60 @@
+package debug
+
+import (
+	"net/http"
+	"time"
+
+	"modules/binder"
+	"modules/errors"
+	"modules/responser"
+	"modules/zerolog"
+
+	"github.com/labstack/echo"
+)
+
+// levelRequest 修改日志级别参数
+type levelRequest struct {
+	Name  string `param:"name" json:"-"`
+	Sink  string `json:"sink" query:"sink" form:"sink"`                        // 为空时修改日志本身
+	Level string `json:"level" query:"level" form:"level" validate:"required"` // debug, info, warn, error, fatal, panic
+	TTL   string `json:"ttl" query:"ttl" form:"ttl"`                           // 自动恢复时间，如 10m，为空不恢复
+}
+
+// Loggers 日志及各输出的当前级别
+func Loggers(c echo.Context) error {
+	return responser.R(c, http.StatusOK, zerolog.GetLevels())
+}
+
+// SetLoggerLevel 修改日志级别，返回修改后的级别列表
+func SetLoggerLevel(c echo.Context) error {
+	req := new(levelRequest)
+	if err := binder.Bind(c, req); err != nil {
+		return err
+	}
+
+	level, err := zerolog.ParseLevel(req.Level)
+	if err != nil {
+		return errors.ErrBadRequest.WithMessage(err.Error())
+	}
+
+	var ttl time.Duration
+	if len(req.TTL) > 0 {
+		if ttl, err = time.ParseDuration(req.TTL); err != nil || ttl < 0 {
+			return errors.ErrBadRequest.WithMessage("invalid ttl " + req.TTL)
+		}
+	}
+
+	if err = zerolog.SetLevel(req.Name, req.Sink, level, ttl); err != nil {
+		return errors.ErrNotFound.WithMessage(err.Error())
+	}
+
+	logger := zerolog.FromEcho(c)
+	logger.Info().
+		Str("logger", req.Name).
+		Str("sink", req.Sink).
+		Str("new_level", level.String()).
+		Dur("ttl", ttl).
+		Msg("log level changed")
+
+	return Loggers(c)
+}
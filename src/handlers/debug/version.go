@@ -9,7 +9,17 @@ import (
 	"github.com/labstack/echo"
 )
 
+// versionInfo 版本及当前环境
+type versionInfo struct {
+	Version string `json:"version"`
+	Profile string `json:"profile"`
+}
+
 // Version Version
 func Version(c echo.Context) error {
-	return responser.R(c, http.StatusOK, setting.Conf.Version)
+	conf := setting.Get()
+	return responser.R(c, http.StatusOK, versionInfo{
+		Version: conf.Version,
+		Profile: conf.Profile,
+	})
 }
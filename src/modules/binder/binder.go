@@ -0,0 +1,322 @@
+package binder
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"modules/errors"
+	"modules/validator"
+
+	"github.com/labstack/echo"
+)
+
+// 绑定来源 tag，按顺序绑定，后面的覆盖前面的
+const (
+	TagDefault = "default"
+	TagForm    = "form"
+	TagQuery   = "query"
+	TagHeader  = "header"
+	TagParam   = "param"
+)
+
+// Bind 绑定并验证请求参数
+// 顺序：default 默认值 -> json/xml/form 请求体 -> query -> header -> param 路由参数
+// 绑定失败和验证失败都返回结构化的 400 错误
+func Bind(c echo.Context, i interface{}) error {
+	if err := BindOnly(c, i); err != nil {
+		return err
+	}
+
+	return c.Validate(i)
+}
+
+// BindOnly 只绑定不验证
+func BindOnly(c echo.Context, i interface{}) error {
+	val := reflect.ValueOf(i)
+	if val.Kind() != reflect.Ptr || val.Elem().Kind() != reflect.Struct {
+		return errors.ErrInternal.WithMessage("binder: i must be a pointer to struct")
+	}
+	val = val.Elem()
+
+	if err := bindDefaults(val); err != nil {
+		return errors.ErrInternal.WithCause(err)
+	}
+
+	if err := bindBody(c, val, i); err != nil {
+		return err
+	}
+
+	req := c.Request()
+
+	sources := []struct {
+		tag  string
+		data map[string][]string
+	}{
+		{TagQuery, c.QueryParams()},
+		{TagHeader, req.Header},
+		{TagParam, paramValues(c)},
+	}
+
+	for _, s := range sources {
+		if len(s.data) == 0 {
+			continue
+		}
+		if err := bindData(val, s.data, s.tag); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func bindBody(c echo.Context, val reflect.Value, i interface{}) error {
+	req := c.Request()
+	if req.ContentLength == 0 || req.Method == echo.GET || req.Method == echo.DELETE || req.Method == echo.HEAD {
+		return nil
+	}
+
+	ctype := req.Header.Get(echo.HeaderContentType)
+	switch {
+	case strings.HasPrefix(ctype, echo.MIMEApplicationJSON):
+		if err := json.NewDecoder(req.Body).Decode(i); err != nil && err != io.EOF {
+			if ute, ok := err.(*json.UnmarshalTypeError); ok {
+				return fieldError(ute.Field, "type", ute.Type.String(), err)
+			}
+			return errors.ErrBadRequest.WithMessage("invalid json body").WithCause(err)
+		}
+
+	case strings.HasPrefix(ctype, echo.MIMEApplicationXML), strings.HasPrefix(ctype, echo.MIMETextXML):
+		if err := xml.NewDecoder(req.Body).Decode(i); err != nil && err != io.EOF {
+			return errors.ErrBadRequest.WithMessage("invalid xml body").WithCause(err)
+		}
+
+	case strings.HasPrefix(ctype, echo.MIMEApplicationForm), strings.HasPrefix(ctype, echo.MIMEMultipartForm):
+		params, err := c.FormParams()
+		if err != nil {
+			return errors.ErrBadRequest.WithMessage("invalid form body").WithCause(err)
+		}
+		return bindData(val, params, TagForm)
+
+	default:
+		return errors.New(http.StatusUnsupportedMediaType, http.StatusUnsupportedMediaType, "")
+	}
+
+	return nil
+}
+
+// bindDefaults 按 default tag 设置默认值，嵌套结构体递归处理
+func bindDefaults(val reflect.Value) error {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		tf := typ.Field(i)
+		field := val.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+
+		def, ok := tf.Tag.Lookup(TagDefault)
+		if !ok {
+			if field.Kind() == reflect.Struct && !isScalar(field) {
+				if err := bindDefaults(field); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if err := setField(field, []string{def}); err != nil {
+			return fmt.Errorf("default %s: %v", tf.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// bindData 绑定指定 tag 的字段，未设置 tag 的字段不绑定
+func bindData(val reflect.Value, data map[string][]string, tag string) error {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		tf := typ.Field(i)
+		field := val.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+
+		name := strings.SplitN(tf.Tag.Get(tag), ",", 2)[0]
+		if name == "-" {
+			continue
+		}
+		if len(name) == 0 {
+			if field.Kind() == reflect.Struct && !isScalar(field) {
+				if err := bindData(field, data, tag); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		values, ok := data[name]
+		if !ok && tag == TagHeader {
+			values, ok = data[http.CanonicalHeaderKey(name)]
+		}
+		if !ok || len(values) == 0 {
+			continue
+		}
+		// 空值如 ?page= 不覆盖默认值，字符串除外
+		if len(values) == 1 && len(strings.TrimSpace(values[0])) == 0 && !isString(field.Type()) {
+			continue
+		}
+
+		if err := setField(field, values); err != nil {
+			return fieldError(name, "type", field.Type().String(), err)
+		}
+	}
+
+	return nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// isScalar 作为单个值绑定的结构体，如 time.Time 和实现 BindUnmarshaler 的类型
+func isScalar(field reflect.Value) bool {
+	if field.Type() == timeType {
+		return true
+	}
+	_, ok := reflect.New(field.Type()).Interface().(echo.BindUnmarshaler)
+	return ok
+}
+
+// isString 字符串、字符串指针或切片
+func isString(typ reflect.Type) bool {
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.String
+}
+
+func setField(field reflect.Value, values []string) error {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		return setField(field.Elem(), values)
+	}
+
+	if u, ok := field.Addr().Interface().(echo.BindUnmarshaler); ok {
+		return u.UnmarshalParam(values[0])
+	}
+
+	if field.Kind() == reflect.Slice && field.Type().Elem().Kind() != reflect.Uint8 {
+		// 非字符串切片，单个值支持逗号分隔，如 ids=1,2,3
+		if len(values) == 1 && field.Type().Elem().Kind() != reflect.String && strings.Contains(values[0], ",") {
+			values = strings.Split(values[0], ",")
+		}
+		slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+		for i := range values {
+			if err := setField(slice.Index(i), values[i:i+1]); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+
+	return setValue(field, values[0])
+}
+
+func setValue(field reflect.Value, s string) error {
+	s = strings.TrimSpace(s)
+
+	if field.Type() == timeType {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(s)
+
+	case reflect.Bool:
+		if len(s) == 0 {
+			s = "false"
+		}
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if field.Type() == reflect.TypeOf(time.Duration(0)) {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return err
+			}
+			field.SetInt(int64(d))
+			return nil
+		}
+		if len(s) == 0 {
+			s = "0"
+		}
+		n, err := strconv.ParseInt(s, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if len(s) == 0 {
+			s = "0"
+		}
+		n, err := strconv.ParseUint(s, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+
+	case reflect.Float32, reflect.Float64:
+		if len(s) == 0 {
+			s = "0"
+		}
+		f, err := strconv.ParseFloat(s, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+
+	return nil
+}
+
+func paramValues(c echo.Context) map[string][]string {
+	names := c.ParamNames()
+	values := c.ParamValues()
+
+	data := make(map[string][]string, len(names))
+	for i := range names {
+		if i < len(values) {
+			data[names[i]] = []string{values[i]}
+		}
+	}
+
+	return data
+}
+
+// fieldError 绑定失败，错误处理时与验证失败返回相同结构，按请求语言翻译
+func fieldError(field, rule, param string, err error) error {
+	return &validator.ParamError{Field: field, Rule: rule, Param: param, Err: err}
+}
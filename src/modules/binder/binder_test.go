@@ -0,0 +1,90 @@
+package binder
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"modules/errors"
+	"modules/validator"
+
+	"github.com/labstack/echo"
+)
+
+type typeForm struct {
+	Age  int    `json:"age" query:"age"`
+	Name string `json:"name" query:"name"`
+}
+
+func newContext(req *http.Request) echo.Context {
+	e := echo.New()
+	e.Validator = validator.New()
+	return e.NewContext(req, httptest.NewRecorder())
+}
+
+func TestQueryTypeErrorLocalized(t *testing.T) {
+	c := newContext(httptest.NewRequest(echo.GET, "/?age=abc", nil))
+
+	err := Bind(c, &typeForm{})
+	pe, ok := err.(*validator.ParamError)
+	if !ok {
+		t.Fatalf("want *validator.ParamError, got %T %v", err, err)
+	}
+	if pe.Field != "age" || pe.Rule != "type" || pe.Param != "int" {
+		t.Fatalf("unexpected %+v", pe)
+	}
+
+	if msg := pe.FieldError(validator.LangTranslator("en")).Message; msg != "age must be a valid int" {
+		t.Errorf("en: %q", msg)
+	}
+	if msg := pe.FieldError(validator.LangTranslator("zh-CN")).Message; msg != "age必须是有效的int" {
+		t.Errorf("zh: %q", msg)
+	}
+}
+
+func TestJSONTypeError(t *testing.T) {
+	req := httptest.NewRequest(echo.POST, "/", strings.NewReader(`{"age":"x"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	c := newContext(req)
+
+	err := Bind(c, &typeForm{})
+	if pe, ok := err.(*validator.ParamError); !ok || pe.Field != "age" {
+		t.Fatalf("want age ParamError, got %T %v", err, err)
+	}
+}
+
+func TestTypeErrorConvertedByAcceptLanguage(t *testing.T) {
+	req := httptest.NewRequest(echo.GET, "/?age=abc", nil)
+	req.Header.Set(validator.HeaderAcceptLanguage, "zh-CN,zh;q=0.9")
+	c := newContext(req)
+
+	he := errors.From(c, Bind(c, &typeForm{}), false)
+	if he.Status != http.StatusBadRequest {
+		t.Fatalf("status %d", he.Status)
+	}
+	fields, ok := he.Details.([]validator.FieldError)
+	if !ok || len(fields) != 1 || fields[0].Message != "age必须是有效的int" {
+		t.Fatalf("details %#v", he.Details)
+	}
+}
+
+func TestEmptyValueKeepsDefault(t *testing.T) {
+	type pageForm struct {
+		Page  int    `query:"page" default:"1"`
+		Size  *int   `header:"X-Size" default:"20"`
+		Name  string `query:"name" default:"all"`
+		Fresh bool   `query:"fresh" default:"true"`
+	}
+
+	req := httptest.NewRequest(echo.GET, "/?page=&name=&fresh=", nil)
+	req.Header.Set("X-Size", "")
+
+	var f pageForm
+	if err := Bind(newContext(req), &f); err != nil {
+		t.Fatal(err)
+	}
+	if f.Page != 1 || f.Size == nil || *f.Size != 20 || !f.Fresh || f.Name != "" {
+		t.Fatalf("got %+v", f)
+	}
+}
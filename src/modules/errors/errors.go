@@ -0,0 +1,95 @@
+package errors
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+
+	"modules/responser"
+)
+
+// Error 统一业务错误
+type Error struct {
+	XMLName xml.Name `json:"-" xml:"error"`
+
+	// Code 业务错误码，默认与 Status 相同
+	Code int `json:"code" xml:"code"`
+	// Status http 状态码
+	Status int `json:"-" xml:"-"`
+	// Message 错误描述，直接返回给调用方
+	Message string `json:"message" xml:"message"`
+	// Details 错误详情，如字段错误列表
+	Details interface{} `json:"details,omitempty" xml:"details,omitempty"`
+	// Cause 原始错误，只记录日志，Debug 模式下返回
+	Cause error `json:"-" xml:"-"`
+}
+
+var (
+	_ error              = &Error{}
+	_ responser.Business = &Error{}
+)
+
+// New 创建错误，msg 为空时使用 http 状态描述
+func New(status, code int, msg string) *Error {
+	if len(msg) == 0 {
+		msg = http.StatusText(status)
+	}
+
+	return &Error{
+		Code:    code,
+		Status:  status,
+		Message: msg,
+	}
+}
+
+// Wrap 包装原始错误
+func Wrap(err error, status, code int, msg string) *Error {
+	return New(status, code, msg).WithCause(err)
+}
+
+// Error error
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("code=%d, status=%d, message=%s, cause=%v", e.Code, e.Status, e.Message, e.Cause)
+	}
+	return fmt.Sprintf("code=%d, status=%d, message=%s", e.Code, e.Status, e.Message)
+}
+
+// BusinessCode responser.Business
+func (e *Error) BusinessCode() int { return e.Code }
+
+// BusinessMessage responser.Business
+func (e *Error) BusinessMessage() string { return e.Message }
+
+// BusinessData responser.Business
+func (e *Error) BusinessData() interface{} { return e.Details }
+
+// WithDetails 返回带详情的副本，预定义错误可以安全复用
+func (e *Error) WithDetails(details interface{}) *Error {
+	ne := *e
+	ne.Details = details
+	return &ne
+}
+
+// WithCause 返回带原始错误的副本
+func (e *Error) WithCause(err error) *Error {
+	ne := *e
+	ne.Cause = err
+	return &ne
+}
+
+// WithMessage 返回替换描述的副本
+func (e *Error) WithMessage(msg string) *Error {
+	ne := *e
+	ne.Message = msg
+	return &ne
+}
+
+// 常用错误
+var (
+	ErrBadRequest   = New(http.StatusBadRequest, http.StatusBadRequest, "")
+	ErrUnauthorized = New(http.StatusUnauthorized, http.StatusUnauthorized, "")
+	ErrForbidden    = New(http.StatusForbidden, http.StatusForbidden, "")
+	ErrNotFound     = New(http.StatusNotFound, http.StatusNotFound, "")
+	ErrInternal     = New(http.StatusInternalServerError, http.StatusInternalServerError, "")
+)
@@ -0,0 +1,137 @@
+package errors
+
+import (
+	"net/http"
+
+	"modules/responser"
+	"modules/zerolog"
+
+	"github.com/labstack/echo"
+)
+
+// Converter 将其它类型错误转换为 *Error，无法处理时返回 nil
+type Converter func(c echo.Context, err error) *Error
+
+var converters = []Converter{}
+
+// RegisterConverter 注册错误转换方法，供模块在 init 中调用
+func RegisterConverter(cv Converter) {
+	converters = append(converters, cv)
+}
+
+// Config HTTPErrorHandler 配置
+type Config struct {
+	// Debug 未知错误返回 err.Error()
+	Debug bool
+
+	// Logger 未使用 zerolog.Middleware 时记录错误的 zerolog 名称
+	Logger string
+}
+
+// DefaultConfig 默认配置
+var DefaultConfig = Config{
+	Logger: "default",
+}
+
+// HTTPErrorHandler 使用默认配置的错误处理
+func HTTPErrorHandler() echo.HTTPErrorHandler {
+	return HTTPErrorHandlerWithConfig(DefaultConfig)
+}
+
+// HTTPErrorHandlerWithConfig 统一错误处理，通过 responser.R 返回，zerolog 记录
+func HTTPErrorHandlerWithConfig(config Config) echo.HTTPErrorHandler {
+	if len(config.Logger) == 0 {
+		config.Logger = DefaultConfig.Logger
+	}
+
+	return func(err error, c echo.Context) {
+		he := From(c, err, config.Debug)
+
+		if !c.Response().Committed {
+			var rerr error
+			if c.Request().Method == echo.HEAD { // Issue #608
+				rerr = c.NoContent(he.Status)
+			} else {
+				rerr = responser.R(c, he.Status, he)
+			}
+			if rerr != nil {
+				logError(config, c, he, rerr)
+				return
+			}
+		}
+
+		logError(config, c, he, nil)
+	}
+}
+
+// From 将任意错误转换为 *Error，没有 http 状态码时为 500
+func From(c echo.Context, err error, debug bool) *Error {
+	e := from(c, err, debug)
+	if e.Status == 0 {
+		// 不修改调用方的错误，可能是共用的变量
+		cp := *e
+		cp.Status = http.StatusInternalServerError
+		if len(cp.Message) == 0 {
+			cp.Message = http.StatusText(cp.Status)
+		}
+		e = &cp
+	}
+	return e
+}
+
+func from(c echo.Context, err error, debug bool) *Error {
+	if e, ok := err.(*Error); ok {
+		return e
+	}
+
+	for i := range converters {
+		if e := converters[i](c, err); e != nil {
+			return e
+		}
+	}
+
+	if he, ok := err.(*echo.HTTPError); ok {
+		e := New(he.Code, he.Code, "")
+		switch msg := he.Message.(type) {
+		case string:
+			e.Message = msg
+		case error:
+			e.Message = msg.Error()
+		case nil:
+		default:
+			e.Details = msg
+		}
+		return e.WithCause(err)
+	}
+
+	if debug {
+		return ErrInternal.WithMessage(err.Error()).WithCause(err)
+	}
+
+	return ErrInternal.WithCause(err)
+}
+
+func logError(config Config, c echo.Context, he *Error, renderErr error) {
+	level := "debug"
+	if he.Status >= http.StatusInternalServerError {
+		level = "error"
+	}
+
+	// 请求日志已带 request_id, method, path, remote_ip, route
+	logger := zerolog.FromEcho(c, config.Logger)
+	ev := logger.WithLevel(zerolog.LevelByString(level)).
+		Int("status", he.Status).
+		Int("code", he.Code).
+		Str("uri", zerolog.RedactURI(c.Request().RequestURI))
+
+	if he.Cause != nil {
+		ev = ev.Err(he.Cause)
+	}
+
+	if renderErr != nil {
+		ev.AnErr("render", renderErr).Msg(he.Message)
+		return
+	}
+
+	ev.Msg(he.Message)
+}
@@ -0,0 +1,27 @@
+package errors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo"
+)
+
+func TestHandlerZeroStatus(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = HTTPErrorHandler()
+
+	zero := &Error{Code: 10001, Message: "quota exceeded"}
+	e.GET("/", func(c echo.Context) error { return zero })
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	if zero.Status != 0 {
+		t.Fatal("caller's error modified")
+	}
+}
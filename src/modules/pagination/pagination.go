@@ -0,0 +1,192 @@
+package pagination
+
+import (
+	"net/url"
+	"strconv"
+
+	"modules/responser"
+	"modules/validator"
+
+	"github.com/labstack/echo"
+)
+
+// Option 分页配置
+type Option struct {
+	PageParam   string // 页码参数，默认 page
+	SizeParam   string // 每页数量参数，默认 size
+	CursorParam string // 游标参数，默认 cursor
+
+	DefaultSize int // 默认每页数量
+	MaxSize     int // 每页最大数量，超过时取 MaxSize
+}
+
+// DefaultOption 默认配置
+var DefaultOption = Option{
+	PageParam:   "page",
+	SizeParam:   "size",
+	CursorParam: "cursor",
+	DefaultSize: 20,
+	MaxSize:     100,
+}
+
+var option = DefaultOption
+
+// Init 初始化配置，未设置的项使用 DefaultOption
+func Init(opt Option) {
+	if len(opt.PageParam) == 0 {
+		opt.PageParam = DefaultOption.PageParam
+	}
+	if len(opt.SizeParam) == 0 {
+		opt.SizeParam = DefaultOption.SizeParam
+	}
+	if len(opt.CursorParam) == 0 {
+		opt.CursorParam = DefaultOption.CursorParam
+	}
+	if opt.DefaultSize <= 0 {
+		opt.DefaultSize = DefaultOption.DefaultSize
+	}
+	if opt.MaxSize <= 0 {
+		opt.MaxSize = DefaultOption.MaxSize
+	}
+	if opt.DefaultSize > opt.MaxSize {
+		opt.DefaultSize = opt.MaxSize
+	}
+
+	option = opt
+}
+
+// Page 请求分页参数
+type Page struct {
+	Page   int
+	Size   int
+	Cursor string
+
+	opt Option
+}
+
+// Parse 解析分页参数，page/size 不是正整数时返回 400
+func Parse(c echo.Context) (*Page, error) {
+	return ParseWithOption(c, option)
+}
+
+// ParseWithOption 使用指定配置解析分页参数，如个别接口需要更大的 MaxSize
+func ParseWithOption(c echo.Context, opt Option) (*Page, error) {
+	p := &Page{
+		Page:   1,
+		Size:   opt.DefaultSize,
+		Cursor: c.QueryParam(opt.CursorParam),
+		opt:    opt,
+	}
+
+	if s := c.QueryParam(opt.PageParam); len(s) > 0 {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			return nil, paramError(opt.PageParam, "min", "1", err)
+		}
+		p.Page = n
+	}
+
+	if s := c.QueryParam(opt.SizeParam); len(s) > 0 {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			return nil, paramError(opt.SizeParam, "min", "1", err)
+		}
+		p.Size = n
+	}
+
+	if p.Size > opt.MaxSize {
+		p.Size = opt.MaxSize
+	}
+
+	return p, nil
+}
+
+// Offset 数据库查询 offset
+func (p *Page) Offset() int {
+	return (p.Page - 1) * p.Size
+}
+
+// Limit 数据库查询 limit
+func (p *Page) Limit() int {
+	return p.Size
+}
+
+// Meta 页码分页信息，total 为总数
+func (p *Page) Meta(c echo.Context, total int64) *responser.PageMeta {
+	pages := 0
+	if total > 0 {
+		pages = int((total + int64(p.Size) - 1) / int64(p.Size))
+	}
+
+	meta := &responser.PageMeta{
+		Page:    p.Page,
+		Size:    p.Size,
+		Total:   total,
+		Pages:   pages,
+		HasMore: p.Page < pages,
+	}
+
+	link := func(rel string, page int) {
+		meta.Links = append(meta.Links, responser.Link{
+			Rel: rel,
+			URL: p.url(c, url.Values{p.opt.PageParam: {strconv.Itoa(page)}}),
+		})
+	}
+
+	link("first", 1)
+	if p.Page > 1 {
+		link("prev", p.Page-1)
+	}
+	if p.Page < pages {
+		link("next", p.Page+1)
+	}
+	if pages > 0 {
+		link("last", pages)
+	}
+
+	return meta
+}
+
+// CursorMeta 游标分页信息，next 为下一页游标，为空表示没有更多
+func (p *Page) CursorMeta(c echo.Context, next string) *responser.PageMeta {
+	meta := &responser.PageMeta{
+		Size:       p.Size,
+		Cursor:     p.Cursor,
+		NextCursor: next,
+		HasMore:    len(next) > 0,
+	}
+
+	if len(next) > 0 {
+		meta.Links = append(meta.Links, responser.Link{
+			Rel: "next",
+			URL: p.url(c, url.Values{p.opt.CursorParam: {next}}),
+		})
+	}
+
+	return meta
+}
+
+// url 当前请求地址，替换分页参数
+func (p *Page) url(c echo.Context, set url.Values) string {
+	req := c.Request()
+
+	q := req.URL.Query()
+	q.Set(p.opt.SizeParam, strconv.Itoa(p.Size))
+	for k, v := range set {
+		q[k] = v
+	}
+
+	u := url.URL{
+		Scheme:   c.Scheme(),
+		Host:     req.Host,
+		Path:     req.URL.Path,
+		RawQuery: q.Encode(),
+	}
+
+	return u.String()
+}
+
+// paramError 与验证失败返回相同结构，错误处理时按请求语言翻译
+func paramError(field, rule, param string, err error) error {
+	return &validator.ParamError{Field: field, Rule: rule, Param: param, Key: "integer_min", Err: err}
+}
@@ -0,0 +1,59 @@
+package pagination
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"modules/errors"
+	"modules/validator"
+
+	"github.com/labstack/echo"
+)
+
+func newContext(target, lang string) echo.Context {
+	req := httptest.NewRequest(echo.GET, target, nil)
+	if len(lang) > 0 {
+		req.Header.Set(validator.HeaderAcceptLanguage, lang)
+	}
+	return echo.New().NewContext(req, httptest.NewRecorder())
+}
+
+func TestParse(t *testing.T) {
+	p, err := Parse(newContext("/?page=3&size=1000", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Page != 3 || p.Size != DefaultOption.MaxSize || p.Offset() != 2*DefaultOption.MaxSize {
+		t.Fatalf("unexpected %+v", p)
+	}
+}
+
+func TestParseErrorLocalized(t *testing.T) {
+	cases := []struct {
+		target string
+		lang   string
+		want   string
+	}{
+		{"/?page=0", "", "page must be an integer greater than or equal to 1"},
+		{"/?size=abc", "en", "size must be an integer greater than or equal to 1"},
+		{"/?page=-1", "zh-CN,zh;q=0.9", "page必须是大于或等于1的整数"},
+	}
+
+	for _, tc := range cases {
+		c := newContext(tc.target, tc.lang)
+		_, err := Parse(c)
+		if err == nil {
+			t.Fatalf("%s: want error", tc.target)
+		}
+
+		he := errors.From(c, err, false)
+		if he.Status != http.StatusBadRequest {
+			t.Fatalf("%s: status %d", tc.target, he.Status)
+		}
+		fields, ok := he.Details.([]validator.FieldError)
+		if !ok || len(fields) != 1 || fields[0].Message != tc.want || fields[0].Rule != "min" {
+			t.Errorf("%s: details %#v", tc.target, he.Details)
+		}
+	}
+}
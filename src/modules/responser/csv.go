@@ -0,0 +1,281 @@
+package responser
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CSVTag csv 列名 tag，未设置时使用 json tag，再使用字段名
+var CSVTag = "csv"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// MarshalCSV 结构体切片转 csv，第一行为表头
+// 支持 []struct, []*struct, struct, []map，map 的表头为所有行 key 的并集
+func MarshalCSV(i interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if err := writeCSV(w, reflect.ValueOf(i)); err != nil {
+		return nil, err
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeCSV(w *csv.Writer, val reflect.Value) error {
+	val = indirect(val)
+	if !val.IsValid() {
+		return nil
+	}
+
+	switch val.Kind() {
+	case reflect.Slice, reflect.Array:
+		if val.Len() == 0 {
+			return nil
+		}
+		rows := make([]reflect.Value, 0, val.Len())
+		for i := 0; i < val.Len(); i++ {
+			rows = append(rows, indirect(val.Index(i)))
+		}
+		return writeCSVRows(w, rows)
+
+	case reflect.Struct, reflect.Map:
+		return writeCSVRows(w, []reflect.Value{val})
+	}
+
+	return w.Write([]string{csvValue(val)})
+}
+
+func writeCSVRows(w *csv.Writer, rows []reflect.Value) error {
+	rw := &csvRowWriter{w: w}
+
+	// map 行的表头取所有行 key 的并集
+	if len(rows) > 0 && rows[0].Kind() == reflect.Map {
+		keys := []reflect.Value{}
+		for _, row := range rows {
+			if row.Kind() == reflect.Map && row.Type() == rows[0].Type() {
+				keys = append(keys, row.MapKeys()...)
+			}
+		}
+		rw.setKeys(keys)
+	}
+
+	for _, row := range rows {
+		if err := rw.write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// csvRowWriter 逐行写入，第一行时按行类型写表头
+// map 行未预先设置 keys 时按第一行的 key 写表头，之后的行有表头中没有的 key 时返回错误
+type csvRowWriter struct {
+	w       *csv.Writer
+	started bool
+	typ     reflect.Type
+	cols    []CSVColumn
+	keys    []reflect.Value
+	names   map[string]bool
+}
+
+// setKeys 设置 map 表头，去重后按字符串排序，保留原始 key 用于取值
+func (rw *csvRowWriter) setKeys(keys []reflect.Value) {
+	rw.keys = make([]reflect.Value, 0, len(keys))
+	rw.names = make(map[string]bool, len(keys))
+	for _, k := range keys {
+		name := fmt.Sprint(k.Interface())
+		if rw.names[name] {
+			continue
+		}
+		rw.names[name] = true
+		rw.keys = append(rw.keys, k)
+	}
+	sort.Slice(rw.keys, func(i, j int) bool {
+		return fmt.Sprint(rw.keys[i].Interface()) < fmt.Sprint(rw.keys[j].Interface())
+	})
+}
+
+func (rw *csvRowWriter) write(row reflect.Value) error {
+	row = indirect(row)
+	if !row.IsValid() {
+		return nil
+	}
+
+	if !rw.started {
+		rw.started = true
+		rw.typ = row.Type()
+
+		switch row.Kind() {
+		case reflect.Struct:
+			rw.cols = CSVColumns(rw.typ)
+			header := make([]string, 0, len(rw.cols))
+			for _, col := range rw.cols {
+				header = append(header, col.Name)
+			}
+			if err := rw.w.Write(header); err != nil {
+				return err
+			}
+
+		case reflect.Map:
+			if rw.names == nil {
+				rw.setKeys(row.MapKeys())
+			}
+			header := make([]string, 0, len(rw.keys))
+			for _, k := range rw.keys {
+				header = append(header, fmt.Sprint(k.Interface()))
+			}
+			if err := rw.w.Write(header); err != nil {
+				return err
+			}
+		}
+	}
+
+	if row.Kind() != rw.typ.Kind() || ((row.Kind() == reflect.Struct || row.Kind() == reflect.Map) && row.Type() != rw.typ) {
+		return fmt.Errorf("csv: mixed row types %s and %s", rw.typ, row.Type())
+	}
+
+	var record []string
+	switch row.Kind() {
+	case reflect.Struct:
+		record = make([]string, 0, len(rw.cols))
+		for _, col := range rw.cols {
+			record = append(record, csvValue(fieldByIndex(row, col.Index)))
+		}
+
+	case reflect.Map:
+		for _, k := range row.MapKeys() {
+			if name := fmt.Sprint(k.Interface()); !rw.names[name] {
+				return fmt.Errorf("csv: key %q not in header", name)
+			}
+		}
+		record = make([]string, 0, len(rw.keys))
+		for _, k := range rw.keys {
+			record = append(record, csvValue(row.MapIndex(k)))
+		}
+
+	default:
+		record = []string{csvValue(row)}
+	}
+
+	return rw.w.Write(record)
+}
+
+// CSVColumn csv 列
+type CSVColumn struct {
+	Name  string
+	Index []int
+}
+
+// CSVColumns 结构体的 csv 列，嵌入的结构体展开
+func CSVColumns(typ reflect.Type) []CSVColumn {
+	cols := []CSVColumn{}
+	for i := 0; i < typ.NumField(); i++ {
+		fld := typ.Field(i)
+		if len(fld.PkgPath) > 0 && !fld.Anonymous {
+			continue
+		}
+
+		name, ok := csvName(fld)
+		if name == "-" {
+			continue
+		}
+
+		ft := fld.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if fld.Anonymous && !ok && ft.Kind() == reflect.Struct {
+			for _, col := range CSVColumns(ft) {
+				col.Index = append([]int{i}, col.Index...)
+				cols = append(cols, col)
+			}
+			continue
+		}
+		if len(fld.PkgPath) > 0 {
+			continue
+		}
+
+		cols = append(cols, CSVColumn{Name: name, Index: []int{i}})
+	}
+
+	return cols
+}
+
+// csvName 返回列名，以及是否由 tag 指定
+func csvName(fld reflect.StructField) (string, bool) {
+	for _, tag := range []string{CSVTag, "json"} {
+		name := strings.SplitN(fld.Tag.Get(tag), ",", 2)[0]
+		if len(name) > 0 {
+			return name, true
+		}
+	}
+	return fld.Name, false
+}
+
+// fieldByIndex 嵌入的结构体指针为空时返回无效值，不 panic
+func fieldByIndex(val reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 {
+			if val = indirect(val); !val.IsValid() {
+				return val
+			}
+		}
+		val = val.Field(x)
+	}
+	return val
+}
+
+func indirect(val reflect.Value) reflect.Value {
+	for val.IsValid() && (val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface) {
+		if val.IsNil() {
+			return reflect.Value{}
+		}
+		val = val.Elem()
+	}
+	return val
+}
+
+func csvValue(val reflect.Value) string {
+	val = indirect(val)
+	if !val.IsValid() {
+		return ""
+	}
+
+	if val.Type() == timeType {
+		return val.Interface().(time.Time).Format(time.RFC3339)
+	}
+
+	if s, ok := val.Interface().(fmt.Stringer); ok {
+		return s.String()
+	}
+
+	switch val.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		if (val.Kind() == reflect.Slice || val.Kind() == reflect.Map) && val.IsNil() {
+			return ""
+		}
+		if val.Kind() == reflect.Slice && val.Type().Elem().Kind() == reflect.Uint8 {
+			return string(val.Bytes())
+		}
+		b, err := json.Marshal(val.Interface())
+		if err != nil {
+			return fmt.Sprint(val.Interface())
+		}
+		return string(b)
+	}
+
+	return fmt.Sprint(val.Interface())
+}
@@ -0,0 +1,66 @@
+package responser
+
+import (
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+)
+
+func TestMarshalCSVNonStringKeys(t *testing.T) {
+	b, err := MarshalCSV([]map[int]string{{2: "b", 10: "j"}, {2: "bb", 10: "jj"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 表头按字符串排序
+	want := "10,2\nj,b\njj,bb\n"
+	if string(b) != want {
+		t.Fatalf("got %q, want %q", b, want)
+	}
+}
+
+func TestMarshalCSVKeyUnion(t *testing.T) {
+	b, err := MarshalCSV([]map[string]interface{}{
+		{"id": 1, "name": "a"},
+		{"id": 2, "email": "b@example.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "email,id,name\n,1,a\nb@example.com,2,\n"
+	if string(b) != want {
+		t.Fatalf("got %q, want %q", b, want)
+	}
+}
+
+func TestCSVRowWriterUnknownKey(t *testing.T) {
+	var buf strings.Builder
+	rw := &csvRowWriter{w: csv.NewWriter(&buf)}
+
+	if err := rw.write(reflect.ValueOf(map[string]int{"a": 1})); err != nil {
+		t.Fatal(err)
+	}
+	if err := rw.write(reflect.ValueOf(map[string]int{"a": 2, "b": 3})); err == nil {
+		t.Fatal("want error for key not in header")
+	}
+}
+
+func TestRCSVIntKeys(t *testing.T) {
+	req := httptest.NewRequest(echo.GET, "/", nil)
+	req.Header.Set(echo.HeaderAccept, "text/csv")
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+
+	if err := R(c, http.StatusOK, []map[int]string{{1: "a"}}); err != nil {
+		t.Fatal(err)
+	}
+	if body := rec.Body.String(); body != "1\na\n" {
+		t.Fatalf("got %q", body)
+	}
+}
@@ -0,0 +1,120 @@
+package responser
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+
+	"github.com/labstack/echo"
+	"github.com/vmihailenco/msgpack"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// 内置 MessagePack、YAML、CSV 格式
+func init() {
+	RegisterEncoder(&Encoder{
+		Name:       "msgpack",
+		MIMEs:      []string{echo.MIMEApplicationMsgpack, "application/x-msgpack"},
+		WrapString: true,
+		Encode: func(c echo.Context, i interface{}) ([]byte, error) {
+			return MarshalMsgpack(i)
+		},
+	})
+
+	RegisterEncoder(&Encoder{
+		Name:        "yaml",
+		MIMEs:       []string{"application/x-yaml", "application/yaml", "text/yaml"},
+		ContentType: "application/x-yaml; charset=UTF-8",
+		WrapString:  true,
+		Encode: func(c echo.Context, i interface{}) ([]byte, error) {
+			return MarshalYAML(i)
+		},
+	})
+
+	RegisterEncoder(&Encoder{
+		Name:        "csv",
+		MIMEs:       []string{"text/csv"},
+		ContentType: "text/csv; charset=UTF-8",
+		NoEnvelope:  true,
+		Encode: func(c echo.Context, i interface{}) ([]byte, error) {
+			return MarshalCSV(i)
+		},
+	})
+}
+
+// MarshalMsgpack 字段名与 json 一致
+func MarshalMsgpack(i interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := msgpack.NewEncoder(&buf).UseJSONTag(true).Encode(i); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// MarshalYAML 先转 json 再转 yaml，字段名、omitempty 与 json 一致，保持字段顺序和整数精度
+func MarshalYAML(i interface{}) ([]byte, error) {
+	b, err := json.Marshal(i)
+	if err != nil {
+		return nil, err
+	}
+
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+
+	v, err := yamlValue(d)
+	if err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(v)
+}
+
+// yamlValue 逐个 token 读取一个 json 值，对象转为 yaml.MapSlice 保持字段顺序，整数不转为浮点数
+func yamlValue(d *json.Decoder) (interface{}, error) {
+	tok, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		if t == '{' {
+			m := yaml.MapSlice{}
+			for d.More() {
+				key, err := d.Token()
+				if err != nil {
+					return nil, err
+				}
+				v, err := yamlValue(d)
+				if err != nil {
+					return nil, err
+				}
+				m = append(m, yaml.MapItem{Key: key, Value: v})
+			}
+			_, err = d.Token()
+			return m, err
+		}
+
+		list := []interface{}{}
+		for d.More() {
+			v, err := yamlValue(d)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		_, err = d.Token()
+		return list, err
+
+	case json.Number:
+		if n, err := t.Int64(); err == nil {
+			return n, nil
+		}
+		if n, err := strconv.ParseUint(t.String(), 10, 64); err == nil {
+			return n, nil
+		}
+		return t.Float64()
+	}
+
+	return tok, nil
+}
@@ -0,0 +1,26 @@
+package responser
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMarshalYAMLOrderAndIntegers(t *testing.T) {
+	v := struct {
+		Zone   string   `json:"zone"`
+		ID     int64    `json:"id"`
+		Big    uint64   `json:"big"`
+		Amount float64  `json:"amount"`
+		Tags   []string `json:"tags"`
+	}{"cn", 9007199254740993, math.MaxUint64, 1.5, []string{}}
+
+	b, err := MarshalYAML(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "zone: cn\nid: 9007199254740993\nbig: 18446744073709551615\namount: 1.5\ntags: []\n"
+	if string(b) != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", b, want)
+	}
+}
@@ -0,0 +1,116 @@
+package responser
+
+import (
+	"encoding/xml"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo"
+)
+
+// Option responser 配置
+type Option struct {
+	// Envelope 统一返回 {code, message, data, request_id, timestamp}
+	Envelope bool
+	// Always200 Envelope 模式下 http 状态码总是 200，兼容旧客户端
+	Always200 bool
+	// SuccessCode 成功时的业务码
+	SuccessCode int
+	// SuccessMessage 成功时的描述
+	SuccessMessage string
+}
+
+var option = Option{
+	SuccessMessage: "ok",
+}
+
+// Init 初始化配置
+func Init(opt Option) {
+	if len(opt.SuccessMessage) == 0 {
+		opt.SuccessMessage = "ok"
+	}
+	option = opt
+}
+
+// Business 带业务码的返回值，如 errors.Error
+type Business interface {
+	BusinessCode() int
+	BusinessMessage() string
+	BusinessData() interface{}
+}
+
+// Result 带业务码的返回值
+type Result struct {
+	XMLName xml.Name `json:"-" xml:"result"`
+
+	Code    int         `json:"code" xml:"code"`
+	Message string      `json:"message" xml:"message"`
+	Data    interface{} `json:"data,omitempty" xml:"data,omitempty"`
+}
+
+var _ Business = &Result{}
+
+// WithCode 返回指定业务码，如 responser.R(c, http.StatusOK, responser.WithCode(1001, "pending", data))
+func WithCode(code int, msg string, data interface{}) *Result {
+	return &Result{Code: code, Message: msg, Data: data}
+}
+
+// BusinessCode Business
+func (r *Result) BusinessCode() int { return r.Code }
+
+// BusinessMessage Business
+func (r *Result) BusinessMessage() string { return r.Message }
+
+// BusinessData Business
+func (r *Result) BusinessData() interface{} { return r.Data }
+
+// Envelope 统一返回格式
+type Envelope struct {
+	XMLName xml.Name `json:"-" xml:"response"`
+
+	Code      int         `json:"code" xml:"code"`
+	Message   string      `json:"message" xml:"message"`
+	Data      interface{} `json:"data,omitempty" xml:"data,omitempty"`
+	RequestID string      `json:"request_id,omitempty" xml:"request_id,omitempty"`
+	Timestamp int64       `json:"timestamp" xml:"timestamp"`
+}
+
+// envelope 包装返回值，成功时业务码为 SuccessCode，失败时为 Business 业务码或 http 状态码
+func envelope(c echo.Context, code int, i interface{}) *Envelope {
+	env := &Envelope{
+		RequestID: requestID(c),
+		Timestamp: time.Now().Unix(),
+	}
+
+	switch v := i.(type) {
+	case Business:
+		env.Code = v.BusinessCode()
+		env.Message = v.BusinessMessage()
+		env.Data = v.BusinessData()
+	case string:
+		env.Message = v
+		if code < http.StatusBadRequest {
+			env.Code = option.SuccessCode
+		} else {
+			env.Code = code
+		}
+	default:
+		env.Data = i
+		if code < http.StatusBadRequest {
+			env.Code = option.SuccessCode
+			env.Message = option.SuccessMessage
+		} else {
+			env.Code = code
+			env.Message = http.StatusText(code)
+		}
+	}
+
+	return env
+}
+
+func requestID(c echo.Context) string {
+	if id := c.Response().Header().Get(echo.HeaderXRequestID); len(id) > 0 {
+		return id
+	}
+	return c.Request().Header.Get(echo.HeaderXRequestID)
+}
@@ -0,0 +1,30 @@
+package responser
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestEnvelopeStringSameForAllPaths(t *testing.T) {
+	defer Init(option)
+	Init(Option{Envelope: true})
+
+	var got []Envelope
+	for _, target := range []string{"/", "/?_resfmt=json"} {
+		c, rec := request(target, "")
+		if err := R(c, 200, "hello"); err != nil {
+			t.Fatalf("%s: %v", target, err)
+		}
+
+		var env Envelope
+		if err := json.Unmarshal(rec.Body.Bytes(), &env); err != nil {
+			t.Fatalf("%s: %v: %s", target, err, rec.Body)
+		}
+		env.Timestamp = 0
+		got = append(got, env)
+	}
+
+	if got[0] != got[1] || got[0].Message != "hello" || got[0].Data != nil {
+		t.Fatalf("default %+v, _resfmt=json %+v", got[0], got[1])
+	}
+}
@@ -0,0 +1,151 @@
+package responser
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo"
+)
+
+// mediaRange Accept 中的一项
+type mediaRange struct {
+	typ     string
+	subtype string
+	q       float64
+}
+
+// match 返回匹配精确度：*/* 0，type/* 1，type/subtype 2
+func (m mediaRange) match(mime string) (int, bool) {
+	typ, subtype := splitMime(mime)
+	switch {
+	case m.typ == "*" && m.subtype == "*":
+		return 0, true
+	case m.typ == typ && m.subtype == "*":
+		return 1, true
+	case m.typ == typ && m.subtype == subtype:
+		return 2, true
+	}
+	return 0, false
+}
+
+// anyFormat 只通过 */* 匹配，由调用方决定具体格式
+var anyFormat = &Encoder{Name: "*"}
+
+// parseAccept 解析 Accept，如 application/json, application/xml;q=0.9, */*;q=0.1
+func parseAccept(header string) []mediaRange {
+	ranges := []mediaRange{}
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if len(part) == 0 {
+			continue
+		}
+
+		params := strings.Split(part, ";")
+		m := mediaRange{q: 1}
+		m.typ, m.subtype = splitMime(params[0])
+		if len(m.typ) == 0 || len(m.subtype) == 0 {
+			continue
+		}
+
+		for _, p := range params[1:] {
+			p = strings.TrimSpace(p)
+			if !strings.HasPrefix(p, "q=") {
+				continue
+			}
+			if q, err := strconv.ParseFloat(p[2:], 64); err == nil {
+				m.q = q
+			}
+		}
+
+		ranges = append(ranges, m)
+	}
+
+	return ranges
+}
+
+func splitMime(mime string) (string, string) {
+	if i := strings.Index(mime, ";"); i >= 0 {
+		mime = mime[:i]
+	}
+	mime = strings.ToLower(strings.TrimSpace(mime))
+
+	i := strings.Index(mime, "/")
+	if i < 0 {
+		return mime, ""
+	}
+	return mime[:i], mime[i+1:]
+}
+
+// matchAccept 选择 q 值最高的格式，q 相同时取更精确的匹配，再取 DefaultFormat，最后按注册顺序
+// 没有可接受的格式返回 nil，只匹配到 */* 返回 anyFormat
+// 带 */* 且最想要的格式都不支持时也返回 anyFormat，
+// 如浏览器 text/html,application/xml;q=0.9,*/*;q=0.8 不返回 xml
+func matchAccept(c echo.Context, ranges []mediaRange) *Encoder {
+	var (
+		best            *Encoder
+		bestQ           float64
+		bestSpecificity = -1
+	)
+
+	for _, f := range encoders {
+		if !f.usable(c) {
+			continue
+		}
+
+		q, specificity := acceptQuality(ranges, f.MIMEs)
+		if q <= 0 {
+			continue
+		}
+
+		if q > bestQ || (q == bestQ && specificity > bestSpecificity) ||
+			(q == bestQ && specificity == bestSpecificity && f.Name == DefaultFormat) {
+			best, bestQ, bestSpecificity = f, q, specificity
+		}
+	}
+
+	if best == nil {
+		return nil
+	}
+
+	if bestSpecificity == 0 {
+		return anyFormat
+	}
+
+	maxQ, wildcard := 0.0, false
+	for _, m := range ranges {
+		if m.q > maxQ {
+			maxQ = m.q
+		}
+		if m.typ == "*" && m.subtype == "*" && m.q > 0 {
+			wildcard = true
+		}
+	}
+	if wildcard && bestQ < maxQ {
+		return anyFormat
+	}
+
+	return best
+}
+
+// acceptQuality 每个类型取最精确匹配项的 q 值，再取各类型中最高的
+func acceptQuality(ranges []mediaRange, mimes []string) (float64, int) {
+	bestQ, bestSpecificity := 0.0, -1
+	for _, mime := range mimes {
+		q, specificity := 0.0, -1
+		for _, m := range ranges {
+			s, ok := m.match(mime)
+			if !ok {
+				continue
+			}
+			if s > specificity || (s == specificity && m.q > q) {
+				q, specificity = m.q, s
+			}
+		}
+
+		if q > bestQ || (q == bestQ && specificity > bestSpecificity) {
+			bestQ, bestSpecificity = q, specificity
+		}
+	}
+
+	return bestQ, bestSpecificity
+}
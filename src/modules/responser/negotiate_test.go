@@ -0,0 +1,111 @@
+package responser
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+)
+
+type xmlRow struct {
+	ID   int    `json:"id" xml:"id"`
+	Name string `json:"name" xml:"name"`
+}
+
+func request(target, accept string) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(echo.GET, target, nil)
+	if len(accept) > 0 {
+		req.Header.Set(echo.HeaderAccept, accept)
+	}
+	rec := httptest.NewRecorder()
+	return echo.New().NewContext(req, rec), rec
+}
+
+func TestNegotiateAccept(t *testing.T) {
+	cases := []struct {
+		accept string
+		want   string
+	}{
+		// 浏览器默认 Accept
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8", "json"},
+		{"application/xml", "xml"},
+		{"application/xml, */*", "xml"},
+		{"text/xml;q=0.5, application/json;q=0.9", "json"},
+		{"*/*", "json"},
+		// q 和精确度相同时取 DefaultFormat
+		{"application/xml, application/json", "json"},
+		{"application/*", "json"},
+		{"", "json"},
+	}
+
+	for _, tc := range cases {
+		c, _ := request("/", tc.accept)
+		f, _, err := negotiate(c)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.accept, err)
+		}
+		if f.Name != tc.want {
+			t.Errorf("%q: got %s, want %s", tc.accept, f.Name, tc.want)
+		}
+	}
+}
+
+func TestNegotiateNotAcceptable(t *testing.T) {
+	c, _ := request("/", "image/png")
+	if _, _, err := negotiate(c); err != ErrNotAcceptable {
+		t.Fatalf("got %v, want ErrNotAcceptable", err)
+	}
+}
+
+func TestVaryNotDuplicated(t *testing.T) {
+	c, rec := request("/", "image/png")
+	if err := R(c, http.StatusOK, "ok"); err != ErrNotAcceptable {
+		t.Fatalf("got %v, want ErrNotAcceptable", err)
+	}
+	// 错误处理中再次返回
+	if err := R(c, http.StatusNotAcceptable, "not acceptable"); err != nil {
+		t.Fatal(err)
+	}
+
+	if vary := rec.Header()[echo.HeaderVary]; len(vary) != 1 || vary[0] != echo.HeaderAccept {
+		t.Fatalf("Vary = %q", vary)
+	}
+}
+
+func TestXMLSliceRoot(t *testing.T) {
+	c, rec := request("/", "application/xml")
+	if err := R(c, http.StatusOK, []xmlRow{{1, "a"}, {2, "b"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	body := strings.TrimPrefix(rec.Body.String(), `<?xml version="1.0" encoding="UTF-8"?>`+"\n")
+	want := "<items><item><id>1</id><name>a</name></item><item><id>2</id><name>b</name></item></items>"
+	if body != want {
+		t.Fatalf("got %s, want %s", body, want)
+	}
+}
+
+func TestStringWrap(t *testing.T) {
+	cases := []struct {
+		target string
+		accept string
+		want   string
+	}{
+		{"/", "", `{"message":"hello"}`},
+		{"/", "application/json", `{"message":"hello"}`},
+		// _resfmt 指定格式时与原有行为一致，不包装
+		{"/?_resfmt=json", "", `"hello"`},
+	}
+
+	for _, tc := range cases {
+		c, rec := request(tc.target, tc.accept)
+		if err := R(c, http.StatusOK, "hello"); err != nil {
+			t.Fatal(err)
+		}
+		if body := rec.Body.String(); body != tc.want {
+			t.Errorf("%s %q: got %s, want %s", tc.target, tc.accept, body, tc.want)
+		}
+	}
+}
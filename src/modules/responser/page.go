@@ -0,0 +1,82 @@
+package responser
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo"
+)
+
+// 分页响应头
+const (
+	HeaderLink       = "Link"
+	HeaderTotalCount = "X-Total-Count"
+)
+
+// PageMeta 分页信息
+type PageMeta struct {
+	// 页码分页
+	Page  int   `json:"page,omitempty" xml:"page,omitempty"`
+	Size  int   `json:"size" xml:"size"`
+	Total int64 `json:"total,omitempty" xml:"total,omitempty"`
+	Pages int   `json:"pages,omitempty" xml:"pages,omitempty"`
+
+	// 游标分页
+	Cursor     string `json:"cursor,omitempty" xml:"cursor,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty" xml:"next_cursor,omitempty"`
+
+	HasMore bool `json:"has_more" xml:"has_more"`
+
+	// Links 写入 Link 响应头
+	Links []Link `json:"-" xml:"-"`
+}
+
+// Link RFC 5988 Link
+type Link struct {
+	Rel string
+	URL string
+}
+
+// PageResult 分页返回值
+type PageResult struct {
+	XMLName xml.Name `json:"-" xml:"page"`
+
+	Items      interface{} `json:"items" xml:"items"`
+	Pagination *PageMeta   `json:"pagination,omitempty" xml:"pagination,omitempty"`
+}
+
+// Raw csv 等表格格式只返回 Items
+func (p *PageResult) Raw() interface{} {
+	return p.Items
+}
+
+// Rawer 表格格式(Encoder.NoEnvelope)返回的原始数据
+type Rawer interface {
+	Raw() interface{}
+}
+
+// RPage 分页返回，分页信息同时写入 body 与 Link、X-Total-Count 响应头
+// meta 为 nil 时只返回 items
+func RPage(c echo.Context, code int, items interface{}, meta *PageMeta) error {
+	if meta == nil {
+		return R(c, code, &PageResult{Items: items})
+	}
+
+	header := c.Response().Header()
+
+	if len(meta.Links) > 0 {
+		links := make([]string, 0, len(meta.Links))
+		for _, l := range meta.Links {
+			links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, l.URL, l.Rel))
+		}
+		header.Set(HeaderLink, strings.Join(links, ", "))
+	}
+
+	if meta.Total > 0 || meta.Page > 0 {
+		header.Set(HeaderTotalCount, strconv.FormatInt(meta.Total, 10))
+	}
+
+	return R(c, code, &PageResult{Items: items, Pagination: meta})
+}
@@ -0,0 +1,35 @@
+package responser
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRPageNilMeta(t *testing.T) {
+	c, rec := request("/", "")
+	if err := RPage(c, http.StatusOK, []int{1, 2}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if body := rec.Body.String(); body != `{"items":[1,2]}` {
+		t.Fatalf("got %s", body)
+	}
+	if len(rec.Header().Get(HeaderLink)) > 0 || len(rec.Header().Get(HeaderTotalCount)) > 0 {
+		t.Fatalf("unexpected headers %v", rec.Header())
+	}
+}
+
+func TestRPageHeaders(t *testing.T) {
+	c, rec := request("/", "")
+	meta := &PageMeta{Page: 1, Size: 2, Total: 5, Links: []Link{{Rel: "next", URL: "/?page=2"}}}
+	if err := RPage(c, http.StatusOK, []int{1, 2}, meta); err != nil {
+		t.Fatal(err)
+	}
+
+	if link := rec.Header().Get(HeaderLink); link != `</?page=2>; rel="next"` {
+		t.Fatalf("Link %q", link)
+	}
+	if total := rec.Header().Get(HeaderTotalCount); total != "5" {
+		t.Fatalf("X-Total-Count %q", total)
+	}
+}
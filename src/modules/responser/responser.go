@@ -1,6 +1,10 @@
 package responser
 
 import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"reflect"
 	"strings"
 
 	"github.com/labstack/echo"
@@ -15,43 +19,233 @@ var (
 
 	// DefaultStringResponseField 默认字符串返回值字段
 	DefaultStringResponseField = "message"
+
+	// DefaultFormat 未指定格式时的返回格式
+	DefaultFormat = "json"
 )
 
+// ErrNotAcceptable Accept 中没有支持的格式
+var ErrNotAcceptable = echo.NewHTTPError(http.StatusNotAcceptable)
+
+// Encoder 返回格式编码
+type Encoder struct {
+	// Name _resfmt 参数值
+	Name string
+	// MIMEs Accept 匹配的类型
+	MIMEs []string
+	// ContentType 返回的 Content-Type，默认 MIMEs[0]
+	ContentType string
+	// Available 是否可用，如 jsonp 需要 callback 参数，可为空
+	Available func(c echo.Context) bool
+	// Encode 编码返回值
+	Encode func(c echo.Context, i interface{}) ([]byte, error)
+	// NoEnvelope 表格格式，如 csv，Envelope 模式下不包装，Rawer 只返回原始数据
+	NoEnvelope bool
+	// WrapString 字符串返回值包装为 {message: ...}
+	// 与原有行为一致，_resfmt 或 Content-Type 指定格式时不包装
+	WrapString bool
+}
+
+var encoders = []*Encoder{
+	{
+		Name:        "json",
+		MIMEs:       []string{echo.MIMEApplicationJSON},
+		ContentType: echo.MIMEApplicationJSONCharsetUTF8,
+		WrapString:  true,
+		Encode: func(c echo.Context, i interface{}) ([]byte, error) {
+			if pretty(c) {
+				return json.MarshalIndent(i, "", "  ")
+			}
+			return json.Marshal(i)
+		},
+	},
+	{
+		Name:        "xml",
+		MIMEs:       []string{echo.MIMEApplicationXML, echo.MIMETextXML},
+		ContentType: echo.MIMEApplicationXMLCharsetUTF8,
+		Encode: func(c echo.Context, i interface{}) ([]byte, error) {
+			var (
+				b   []byte
+				err error
+			)
+			i = wrapXMLList(i)
+			if pretty(c) {
+				b, err = xml.MarshalIndent(i, "", "  ")
+			} else {
+				b, err = xml.Marshal(i)
+			}
+			if err != nil {
+				return nil, err
+			}
+			return append([]byte(xml.Header), b...), nil
+		},
+	},
+	{
+		Name:        "jsonp",
+		MIMEs:       []string{echo.MIMEApplicationJavaScript, "text/javascript"},
+		ContentType: echo.MIMEApplicationJavaScriptCharsetUTF8,
+		WrapString:  true,
+		Available: func(c echo.Context) bool {
+			return len(c.QueryParam(DefaultJsonpParam)) > 0
+		},
+		Encode: func(c echo.Context, i interface{}) ([]byte, error) {
+			b, err := json.Marshal(i)
+			if err != nil {
+				return nil, err
+			}
+			callback := c.QueryParam(DefaultJsonpParam)
+			return append(append([]byte(callback+"("), b...), ");"...), nil
+		},
+	},
+}
+
+// RegisterEncoder 注册返回格式，Name 相同时替换，供模块在 init 中调用
+func RegisterEncoder(enc *Encoder) {
+	for i := range encoders {
+		if encoders[i].Name == enc.Name {
+			encoders[i] = enc
+			return
+		}
+	}
+	encoders = append(encoders, enc)
+}
+
 // R 统计一返回值方法
+// 格式优先级：_resfmt 参数 > Accept 协商 > 请求 Content-Type > DefaultFormat
+// Accept 中没有支持的格式时返回 406，错误响应(code >= 400)仍使用默认格式返回
+// Option.Envelope 开启时包装为 Envelope
 func R(c echo.Context, code int, i interface{}) error {
+	f, explicit, err := negotiate(c)
+	if err != nil {
+		if code < http.StatusBadRequest {
+			return err
+		}
+		f, explicit = findEncoder(DefaultFormat), false
+	}
 
-	req := c.Request()
+	enveloped := option.Envelope && !f.NoEnvelope
 
-	resFormat := strings.ToLower(c.QueryParam(DefaultFormatParam))
+	// 包装后字符串放在 message 中，与指定格式时一致
+	if f.WrapString && !explicit && !enveloped {
+		i = wrapString(i)
+	}
 
-	ctype := req.Header.Get(echo.HeaderContentType)
-	switch {
-	case resFormat == "json":
+	if raw, ok := i.(Rawer); ok && f.NoEnvelope {
+		i = raw.Raw()
+	}
 
-		return c.JSON(code, i)
+	if enveloped {
+		i = envelope(c, code, i)
+		if option.Always200 {
+			code = http.StatusOK
+		}
+	}
 
-	case resFormat == "jsonp":
+	b, err := f.Encode(c, i)
+	if err != nil {
+		return err
+	}
+
+	return c.Blob(code, f.contentType(), b)
+}
+
+// negotiate 选择返回格式，explicit 表示由 _resfmt 或 Content-Type 指定
+func negotiate(c echo.Context) (f *Encoder, explicit bool, err error) {
+	req := c.Request()
 
-		return c.JSONP(code, c.QueryParam(DefaultJsonpParam), i)
+	if name := strings.ToLower(c.QueryParam(DefaultFormatParam)); len(name) > 0 {
+		if f := findEncoder(name); f != nil && f.usable(c) {
+			return f, true, nil
+		}
+	}
 
-	case resFormat == "xml":
+	addVary(c.Response().Header(), echo.HeaderAccept)
+
+	if accept := req.Header.Get(echo.HeaderAccept); len(accept) > 0 {
+		f := matchAccept(c, parseAccept(accept))
+		if f == nil {
+			return nil, false, ErrNotAcceptable
+		}
+		// */* 时继续按 Content-Type 判断
+		if f != anyFormat {
+			return f, false, nil
+		}
+	}
 
-		return c.XML(code, i)
+	ctype := req.Header.Get(echo.HeaderContentType)
+	for _, f := range encoders {
+		if !f.usable(c) {
+			continue
+		}
+		for _, mime := range f.MIMEs {
+			if strings.HasPrefix(ctype, mime) {
+				return f, true, nil
+			}
+		}
+	}
 
-	case strings.HasPrefix(ctype, echo.MIMEApplicationJSON):
+	return findEncoder(DefaultFormat), false, nil
+}
 
-		return c.JSON(code, i)
+// addVary 已有时不重复添加，错误处理中再次调用 R 时
+func addVary(h http.Header, name string) {
+	for _, v := range h[echo.HeaderVary] {
+		for _, part := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), name) {
+				return
+			}
+		}
+	}
+	h.Add(echo.HeaderVary, name)
+}
 
-	case strings.HasPrefix(ctype, echo.MIMEApplicationXML), strings.HasPrefix(ctype, echo.MIMETextXML):
+func (f *Encoder) usable(c echo.Context) bool {
+	return f.Available == nil || f.Available(c)
+}
 
-		return c.XML(code, i)
+func (f *Encoder) contentType() string {
+	if len(f.ContentType) > 0 {
+		return f.ContentType
+	}
+	return f.MIMEs[0]
+}
 
+func findEncoder(name string) *Encoder {
+	for _, f := range encoders {
+		if f.Name == name {
+			return f
+		}
 	}
+	return nil
+}
+
+// pretty 与 echo 一致，Debug 模式或带 pretty 参数时格式化输出
+func pretty(c echo.Context) bool {
+	_, ok := c.QueryParams()["pretty"]
+	return ok || c.Echo().Debug
+}
 
-	// 字符串处理
+// 字符串处理
+func wrapString(i interface{}) interface{} {
 	if _, ok := i.(string); ok {
-		return c.JSON(code, echo.Map{DefaultStringResponseField: i})
+		return echo.Map{DefaultStringResponseField: i}
+	}
+	return i
+}
+
+// xmlList xml 只能有一个根元素，切片放在 items 中，每项为 item
+type xmlList struct {
+	XMLName xml.Name    `xml:"items"`
+	Items   interface{} `xml:"item"`
+}
+
+func wrapXMLList(i interface{}) interface{} {
+	v := reflect.ValueOf(i)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type().Elem().Kind() != reflect.Uint8 {
+		return xmlList{Items: i}
 	}
-	// 默认返回json
-	return c.JSON(code, i)
+	return i
 }
@@ -0,0 +1,213 @@
+package responser
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo"
+)
+
+// 流式返回类型
+const (
+	MIMEApplicationNDJSON = "application/x-ndjson"
+	MIMETextEventStream   = "text/event-stream"
+)
+
+// Iterator 流式数据源，没有更多数据时返回 io.EOF
+type Iterator func(ctx context.Context) (interface{}, error)
+
+// FromChan channel 转 Iterator，channel 关闭时结束，ch 可以是任意类型的 channel
+func FromChan(ch interface{}) Iterator {
+	cv := reflect.ValueOf(ch)
+	if cv.Kind() != reflect.Chan {
+		panic("responser: FromChan requires a channel")
+	}
+
+	return func(ctx context.Context) (interface{}, error) {
+		chosen, v, ok := reflect.Select([]reflect.SelectCase{
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+			{Dir: reflect.SelectRecv, Chan: cv},
+		})
+		if chosen == 0 {
+			return nil, ctx.Err()
+		}
+		if !ok {
+			return nil, io.EOF
+		}
+		return v.Interface(), nil
+	}
+}
+
+// Event SSE 事件，Data 为字符串时原样输出，否则输出 json
+type Event struct {
+	ID    string
+	Event string
+	Retry time.Duration
+	Data  interface{}
+}
+
+// StreamNDJSON 每项一行 json
+func StreamNDJSON(c echo.Context, code int, it Iterator) error {
+	return stream(c, code, MIMEApplicationNDJSON, it, func(w io.Writer, item interface{}) error {
+		return json.NewEncoder(w).Encode(item)
+	})
+}
+
+// StreamSSE Server-Sent Events，item 为 Event 或 *Event 时按事件输出，否则作为 data
+func StreamSSE(c echo.Context, code int, it Iterator) error {
+	res := c.Response()
+	res.Header().Set("Cache-Control", "no-cache")
+	res.Header().Set("Connection", "keep-alive")
+	// nginx 不缓冲
+	res.Header().Set("X-Accel-Buffering", "no")
+
+	// 先返回响应头，客户端可以立即确认连接
+	res.Header().Set(echo.HeaderContentType, MIMETextEventStream)
+	res.WriteHeader(code)
+	flush(c)
+
+	return stream(c, code, MIMETextEventStream, it, writeEvent)
+}
+
+// StreamCSV 逐行输出 csv，第一行按第一项的类型输出表头
+// map 项的表头为第一项的 key，之后的项有其他 key 时返回错误
+func StreamCSV(c echo.Context, code int, it Iterator) error {
+	var rw *csvRowWriter
+	return stream(c, code, "text/csv; charset=UTF-8", it, func(w io.Writer, item interface{}) error {
+		if rw == nil {
+			rw = &csvRowWriter{w: csv.NewWriter(w)}
+		}
+		if err := rw.write(reflect.ValueOf(item)); err != nil {
+			return err
+		}
+		rw.w.Flush()
+		return rw.w.Error()
+	})
+}
+
+// stream 逐项写入并 flush，客户端断开时停止
+// 第一项前出错时响应还未提交，错误可以由 HTTPErrorHandler 正常返回
+func stream(c echo.Context, code int, ctype string, it Iterator, write func(w io.Writer, item interface{}) error) error {
+	ctx := c.Request().Context()
+	res := c.Response()
+
+	commit := func() {
+		if !res.Committed {
+			res.Header().Set(echo.HeaderContentType, ctype)
+			res.WriteHeader(code)
+		}
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil
+		}
+
+		item, err := it(ctx)
+		if err == io.EOF {
+			commit()
+			flush(c)
+			return nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				// 客户端已断开
+				return nil
+			}
+			return err
+		}
+
+		commit()
+		if err = write(res, item); err != nil {
+			return err
+		}
+		flush(c)
+	}
+}
+
+func writeEvent(w io.Writer, item interface{}) error {
+	var ev Event
+	switch v := item.(type) {
+	case Event:
+		ev = v
+	case *Event:
+		ev = *v
+	default:
+		ev.Data = item
+	}
+
+	var buf []byte
+	if len(ev.ID) > 0 {
+		buf = append(buf, "id: "+ev.ID+"\n"...)
+	}
+	if len(ev.Event) > 0 {
+		buf = append(buf, "event: "+ev.Event+"\n"...)
+	}
+	if ev.Retry > 0 {
+		buf = append(buf, "retry: "+strconv.FormatInt(int64(ev.Retry/time.Millisecond), 10)+"\n"...)
+	}
+
+	var data string
+	switch v := ev.Data.(type) {
+	case string:
+		data = v
+	case []byte:
+		data = string(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		data = string(b)
+	}
+	for _, line := range strings.Split(data, "\n") {
+		buf = append(buf, "data: "+line+"\n"...)
+	}
+	buf = append(buf, '\n')
+
+	_, err := w.Write(buf)
+	return err
+}
+
+// flush 刷新响应
+// Gzip 中间件的 Flush 只刷新 gzip.Writer，这里继续刷新被包装的 http.ResponseWriter，
+// 否则数据会停留在 net/http 的缓冲区
+func flush(c echo.Context) {
+	res := c.Response()
+	if !res.Committed {
+		return
+	}
+	res.Flush()
+
+	w := res.Writer
+	for {
+		v := reflect.Indirect(reflect.ValueOf(w))
+		if v.Kind() != reflect.Struct {
+			return
+		}
+		f := v.FieldByName("ResponseWriter")
+		if !f.IsValid() || !f.CanInterface() {
+			return
+		}
+		inner, ok := f.Interface().(http.ResponseWriter)
+		if !ok || inner == nil {
+			return
+		}
+		if fl, ok := inner.(http.Flusher); ok {
+			fl.Flush()
+		}
+		w = inner
+	}
+}
+
+// StreamGzipSkipper Gzip 中间件 Skipper，SSE 请求不压缩
+func StreamGzipSkipper(c echo.Context) bool {
+	return strings.Contains(c.Request().Header.Get(echo.HeaderAccept), MIMETextEventStream)
+}
@@ -0,0 +1,81 @@
+package validator
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"modules/errors"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/labstack/echo"
+
+	validator "gopkg.in/go-playground/validator.v9"
+)
+
+// FieldError 字段验证错误
+type FieldError struct {
+	// Field 字段路径，取 json/query 等 tag 名，如 user.name
+	Field string `json:"field" xml:"field"`
+	// Rule 未通过的验证规则，如 required, min
+	Rule string `json:"rule" xml:"rule"`
+	// Param 规则参数，如 min=3 中的 3
+	Param string `json:"param,omitempty" xml:"param,omitempty"`
+	// Message 错误描述
+	Message string `json:"message" xml:"message"`
+}
+
+// ErrValidation 参数验证失败
+var ErrValidation = errors.New(http.StatusBadRequest, http.StatusBadRequest, "invalid params")
+
+// FieldErrors 转换 validator.ValidationErrors，trans 为空时使用默认描述
+func FieldErrors(ve validator.ValidationErrors, trans ut.Translator) []FieldError {
+	fields := make([]FieldError, 0, len(ve))
+	for _, fe := range ve {
+		fields = append(fields, FieldError{
+			Field:   fieldPath(fe),
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: message(fe, trans),
+		})
+	}
+
+	return fields
+}
+
+// fieldPath 去掉 Namespace 中的顶层结构体名
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if i := strings.Index(ns, "."); i >= 0 {
+		return ns[i+1:]
+	}
+
+	return fe.Field()
+}
+
+func message(fe validator.FieldError, trans ut.Translator) string {
+	if trans != nil {
+		// 没有对应翻译时 Translate 返回 fe.Error()
+		if msg := fe.Translate(trans); msg != fe.(error).Error() {
+			return msg
+		}
+	}
+
+	if len(fe.Param()) > 0 {
+		return fmt.Sprintf("%s failed on the '%s=%s' rule", fe.Field(), fe.Tag(), fe.Param())
+	}
+	return fmt.Sprintf("%s failed on the '%s' rule", fe.Field(), fe.Tag())
+}
+
+// 注册到统一错误处理
+func init() {
+	errors.RegisterConverter(func(c echo.Context, err error) *errors.Error {
+		switch e := err.(type) {
+		case validator.ValidationErrors:
+			return ErrValidation.WithDetails(FieldErrors(e, Translator(c))).WithCause(err)
+		case *ParamError:
+			return ErrValidation.WithDetails([]FieldError{e.FieldError(Translator(c))}).WithCause(e.Err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,86 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	ut "github.com/go-playground/universal-translator"
+)
+
+// ParamError 参数绑定或解析失败，如类型不符
+// 错误处理时与验证失败返回相同结构，Message 按请求语言翻译
+type ParamError struct {
+	Field string
+	Rule  string // 返回的规则，如 type, min
+	Param string
+	// Key 文案 key，见 RegisterParamMessages，为空时使用 Rule
+	Key string
+	Err error
+}
+
+// Error error
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("%s failed on the '%s=%s' rule: %v", e.Field, e.Rule, e.Param, e.Err)
+}
+
+// FieldError 转为验证错误，trans 为空时使用 DefaultLang
+func (e *ParamError) FieldError(trans ut.Translator) FieldError {
+	key := e.Key
+	if len(key) == 0 {
+		key = e.Rule
+	}
+
+	return FieldError{
+		Field:   e.Field,
+		Rule:    e.Rule,
+		Param:   e.Param,
+		Message: paramMessage(key, trans, e.Field, e.Param),
+	}
+}
+
+var (
+	paramMu       sync.RWMutex
+	paramMessages = map[string]Messages{
+		"type": {
+			"en": "{0} must be a valid {1}",
+			"zh": "{0}必须是有效的{1}",
+		},
+		"integer_min": {
+			"en": "{0} must be an integer greater than or equal to {1}",
+			"zh": "{0}必须是大于或等于{1}的整数",
+		},
+	}
+)
+
+// RegisterParamMessages 注册 ParamError 的多语言文案，{0} 字段名，{1} 规则参数
+func RegisterParamMessages(key string, msgs Messages) {
+	paramMu.Lock()
+	paramMessages[key] = msgs
+	paramMu.Unlock()
+}
+
+// paramMessage 按 zh_cn, zh, DefaultLang 依次查找
+func paramMessage(key string, trans ut.Translator, field, param string) string {
+	paramMu.RLock()
+	msgs := paramMessages[key]
+	paramMu.RUnlock()
+
+	langs := []string{}
+	if trans != nil {
+		loc := strings.ToLower(trans.Locale())
+		langs = append(langs, loc)
+		if i := strings.Index(loc, "_"); i > 0 {
+			langs = append(langs, loc[:i])
+		}
+	}
+	langs = append(langs, DefaultLang)
+
+	for _, lang := range langs {
+		if text, ok := msgs[lang]; ok {
+			return strings.NewReplacer("{0}", field, "{1}", param).Replace(text)
+		}
+	}
+
+	return fmt.Sprintf("%s failed on the '%s=%s' rule", field, key, param)
+}
@@ -0,0 +1,192 @@
+package validator
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"modules/zerolog"
+
+	ut "github.com/go-playground/universal-translator"
+
+	validator "gopkg.in/go-playground/validator.v9"
+)
+
+// Configurator 配置 validator 实例，pool 中每个实例创建时都会执行
+type Configurator func(v *validator.Validate) error
+
+var configurators = []Configurator{}
+
+// Register 注册配置方法，供模块在 init 中调用，需在 New 之前注册
+func Register(c Configurator) {
+	configurators = append(configurators, c)
+}
+
+// Messages 规则的多语言描述，key 为语言如 en, zh；{0} 字段名，{1} 规则参数
+type Messages map[string]string
+
+// RegisterRule 注册自定义验证规则
+func RegisterRule(tag string, fn validator.Func, msgs Messages) {
+	Register(func(v *validator.Validate) error {
+		if err := v.RegisterValidation(tag, fn); err != nil {
+			return err
+		}
+		return registerMessages(v, tag, msgs)
+	})
+}
+
+// RegisterAlias 注册别名规则，如 RegisterAlias("username", "required,min=4,max=32")
+func RegisterAlias(alias, tags string, msgs Messages) {
+	Register(func(v *validator.Validate) error {
+		v.RegisterAlias(alias, tags)
+		return registerMessages(v, alias, msgs)
+	})
+}
+
+// RegisterStructValidation 注册结构体级验证
+func RegisterStructValidation(fn validator.StructLevelFunc, types ...interface{}) {
+	Register(func(v *validator.Validate) error {
+		v.RegisterStructValidation(fn, types...)
+		return nil
+	})
+}
+
+// RegisterCustomTypeFunc 注册自定义类型取值，如 sql.NullString
+func RegisterCustomTypeFunc(fn validator.CustomTypeFunc, types ...interface{}) {
+	Register(func(v *validator.Validate) error {
+		v.RegisterCustomTypeFunc(fn, types...)
+		return nil
+	})
+}
+
+func registerMessages(v *validator.Validate, tag string, msgs Messages) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	initTranslators()
+
+	for lang, text := range msgs {
+		trans, ok := findTranslator(lang)
+		if !ok {
+			continue
+		}
+
+		text := text
+		err := v.RegisterTranslation(tag, trans, func(trans ut.Translator) error {
+			return trans.Add(tag, text, false)
+		}, func(trans ut.Translator, fe validator.FieldError) string {
+			msg, err := trans.T(tag, fe.Field(), fe.Param())
+			if err != nil {
+				return fe.(error).Error()
+			}
+			return msg
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UniqueChecker 唯一性检查，返回 true 表示 value 未被占用
+type UniqueChecker func(value interface{}) bool
+
+var (
+	uniqueMu       sync.RWMutex
+	uniqueCheckers = map[string]UniqueChecker{}
+)
+
+// RegisterUniqueChecker 注册唯一性检查，使用 validate:"unique=name"
+func RegisterUniqueChecker(name string, fn UniqueChecker) {
+	uniqueMu.Lock()
+	uniqueCheckers[name] = fn
+	uniqueMu.Unlock()
+}
+
+func isUnique(fl validator.FieldLevel) bool {
+	uniqueMu.RLock()
+	fn, ok := uniqueCheckers[fl.Param()]
+	uniqueMu.RUnlock()
+
+	// 请求中不 panic，验证不通过并记录，便于发现漏注册
+	if !ok {
+		zerolog.Error().Str("checker", fl.Param()).Msg("validator: unique checker not registered")
+		return false
+	}
+
+	return fn(fl.Field().Interface())
+}
+
+var mobileRegex = regexp.MustCompile(`^1[3-9]\d{9}$`)
+
+// isMobile 大陆手机号
+func isMobile(fl validator.FieldLevel) bool {
+	return mobileRegex.MatchString(fl.Field().String())
+}
+
+var (
+	idCardRegex   = regexp.MustCompile(`^\d{17}[\dXx]$`)
+	idCardWeights = []int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+	idCardChecks  = "10X98765432"
+)
+
+// isIDCard 18 位居民身份证号，校验最后一位校验码
+func isIDCard(fl validator.FieldLevel) bool {
+	id := fl.Field().String()
+	if !idCardRegex.MatchString(id) {
+		return false
+	}
+
+	sum := 0
+	for i, w := range idCardWeights {
+		sum += int(id[i]-'0') * w
+	}
+
+	return idCardChecks[sum%11] == strings.ToUpper(id[17:])[0]
+}
+
+// isPassword 强密码，8 位以上且同时包含大小写字母和数字
+func isPassword(fl validator.FieldLevel) bool {
+	pwd := fl.Field().String()
+	if utf8.RuneCountInString(pwd) < 8 {
+		return false
+	}
+
+	var upper, lower, digit bool
+	for _, r := range pwd {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		}
+	}
+
+	return upper && lower && digit
+}
+
+// 内置规则
+func init() {
+	RegisterRule("mobile", isMobile, Messages{
+		"en": "{0} must be a valid mobile number",
+		"zh": "{0}必须是有效的手机号码",
+	})
+	RegisterRule("idcard", isIDCard, Messages{
+		"en": "{0} must be a valid ID card number",
+		"zh": "{0}必须是有效的身份证号码",
+	})
+	RegisterRule("password", isPassword, Messages{
+		"en": "{0} must be at least 8 characters and contain upper and lower case letters and digits",
+		"zh": "{0}至少8位，且必须包含大小写字母和数字",
+	})
+	RegisterRule("unique", isUnique, Messages{
+		"en": "{0} already exists",
+		"zh": "{0}已存在",
+	})
+}
@@ -0,0 +1,57 @@
+package validator
+
+import "testing"
+
+type uniqueForm struct {
+	Name string `json:"name" validate:"unique=missing_checker"`
+}
+
+type passwordForm struct {
+	Password string `json:"password" validate:"password"`
+}
+
+func TestUniqueNotRegistered(t *testing.T) {
+	v := New()
+	if err := v.Validate(&uniqueForm{Name: "a"}); err == nil {
+		t.Fatal("want validation error for unregistered checker")
+	}
+}
+
+func TestUniqueRegistered(t *testing.T) {
+	RegisterUniqueChecker("test_names", func(value interface{}) bool {
+		return value.(string) != "taken"
+	})
+
+	type form struct {
+		Name string `json:"name" validate:"unique=test_names"`
+	}
+
+	v := New()
+	if err := v.Validate(&form{Name: "free"}); err != nil {
+		t.Fatalf("free: %v", err)
+	}
+	if err := v.Validate(&form{Name: "taken"}); err == nil {
+		t.Fatal("taken: want error")
+	}
+}
+
+func TestPasswordCountsRunes(t *testing.T) {
+	v := New()
+
+	cases := []struct {
+		pwd string
+		ok  bool
+	}{
+		{"Abcdef12", true},
+		{"Abc12", false},
+		// 6 个字符，按字节数超过 8
+		{"Ab1密码码", false},
+		{"Ab1密码码码码", true},
+	}
+	for _, c := range cases {
+		err := v.Validate(&passwordForm{Password: c.pwd})
+		if (err == nil) != c.ok {
+			t.Errorf("%q: ok=%v, err=%v", c.pwd, c.ok, err)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package validator
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/zh"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/labstack/echo"
+
+	validator "gopkg.in/go-playground/validator.v9"
+	en_translations "gopkg.in/go-playground/validator.v9/translations/en"
+)
+
+// HeaderAcceptLanguage echo 未定义
+const HeaderAcceptLanguage = "Accept-Language"
+
+var (
+	// DefaultLangParam 默认query语言参数，优先于 Accept-Language
+	DefaultLangParam = "lang"
+
+	// DefaultLang 默认语言，找不到匹配语言时使用
+	DefaultLang = "en"
+)
+
+// RegisterTranslationsFunc 向 validator 注册某个语言的全部翻译
+type RegisterTranslationsFunc func(v *validator.Validate, trans ut.Translator) error
+
+type language struct {
+	locale   locales.Translator
+	register RegisterTranslationsFunc
+}
+
+var languages = []language{
+	{locale: en.New(), register: en_translations.RegisterDefaultTranslations},
+	{locale: zh.New(), register: RegisterZhTranslations},
+}
+
+var (
+	transOnce   sync.Once
+	translators map[string]*translator
+)
+
+// RegisterLanguage 注册语言，需在 New 之前调用
+func RegisterLanguage(locale locales.Translator, fn RegisterTranslationsFunc) {
+	languages = append(languages, language{locale: locale, register: fn})
+}
+
+// translator 所有 validator 共享的 ut.Translator
+// validator 按 Translator 保存翻译方法，pool 中每个实例都要注册一遍，
+// 重复注册时文案已存在，忽略冲突错误
+type translator struct {
+	ut.Translator
+}
+
+func (t *translator) Add(key interface{}, text string, override bool) error {
+	return ignoreConflict(t.Translator.Add(key, text, override))
+}
+
+func (t *translator) AddCardinal(key interface{}, text string, rule locales.PluralRule, override bool) error {
+	return ignoreConflict(t.Translator.AddCardinal(key, text, rule, override))
+}
+
+func (t *translator) AddOrdinal(key interface{}, text string, rule locales.PluralRule, override bool) error {
+	return ignoreConflict(t.Translator.AddOrdinal(key, text, rule, override))
+}
+
+func (t *translator) AddRange(key interface{}, text string, rule locales.PluralRule, override bool) error {
+	return ignoreConflict(t.Translator.AddRange(key, text, rule, override))
+}
+
+func ignoreConflict(err error) error {
+	if _, ok := err.(*ut.ErrConflictingTranslation); ok {
+		return nil
+	}
+	return err
+}
+
+func initTranslators() {
+	transOnce.Do(func() {
+		locs := make([]locales.Translator, 0, len(languages))
+		for i := range languages {
+			locs = append(locs, languages[i].locale)
+		}
+
+		uni := ut.New(locs[0], locs...)
+
+		translators = make(map[string]*translator, len(languages))
+		for i := range languages {
+			loc := languages[i].locale.Locale()
+			trans, _ := uni.GetTranslator(loc)
+			translators[strings.ToLower(loc)] = &translator{trans}
+		}
+	})
+}
+
+// registerTranslations 向 validator 注册所有语言翻译
+func registerTranslations(v *validator.Validate) {
+	initTranslators()
+
+	for i := range languages {
+		trans := translators[strings.ToLower(languages[i].locale.Locale())]
+		if err := languages[i].register(v, trans); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Translator 按 query 参数或 Accept-Language 选择翻译
+func Translator(c echo.Context) ut.Translator {
+	initTranslators()
+
+	if lang := c.QueryParam(DefaultLangParam); len(lang) > 0 {
+		if trans, ok := findTranslator(lang); ok {
+			return trans
+		}
+	}
+
+	for _, lang := range acceptLanguages(c.Request().Header.Get(HeaderAcceptLanguage)) {
+		if trans, ok := findTranslator(lang); ok {
+			return trans
+		}
+	}
+
+	trans, _ := findTranslator(DefaultLang)
+	return trans
+}
+
+// LangTranslator 按语言获取翻译，不在请求中时使用，如校验配置，找不到时使用 DefaultLang
+func LangTranslator(lang string) ut.Translator {
+	initTranslators()
+
+	if trans, ok := findTranslator(lang); ok {
+		return trans
+	}
+
+	trans, _ := findTranslator(DefaultLang)
+	return trans
+}
+
+// findTranslator zh-CN 依次匹配 zh_cn, zh
+func findTranslator(lang string) (ut.Translator, bool) {
+	lang = strings.ToLower(strings.Replace(strings.TrimSpace(lang), "-", "_", -1))
+
+	if trans, ok := translators[lang]; ok {
+		return trans, true
+	}
+
+	if i := strings.Index(lang, "_"); i > 0 {
+		if trans, ok := translators[lang[:i]]; ok {
+			return trans, true
+		}
+	}
+
+	return nil, false
+}
+
+// acceptLanguages 解析 Accept-Language，按 q 值从高到低排序
+func acceptLanguages(header string) []string {
+	type lang struct {
+		tag string
+		q   float64
+	}
+
+	langs := []lang{}
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if len(part) == 0 {
+			continue
+		}
+
+		l := lang{tag: part, q: 1}
+		if i := strings.Index(part, ";"); i >= 0 {
+			l.tag = strings.TrimSpace(part[:i])
+			params := strings.TrimSpace(part[i+1:])
+			if strings.HasPrefix(params, "q=") {
+				q, err := strconv.ParseFloat(params[2:], 64)
+				if err != nil {
+					continue
+				}
+				l.q = q
+			}
+		}
+
+		if l.q <= 0 || l.tag == "*" {
+			continue
+		}
+		langs = append(langs, l)
+	}
+
+	sort.SliceStable(langs, func(i, j int) bool {
+		return langs[i].q > langs[j].q
+	})
+
+	tags := make([]string, 0, len(langs))
+	for i := range langs {
+		tags = append(tags, langs[i].tag)
+	}
+
+	return tags
+}
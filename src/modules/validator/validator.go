@@ -1,6 +1,8 @@
 package validator
 
 import (
+	"reflect"
+	"strings"
 	"sync"
 
 	"github.com/labstack/echo"
@@ -10,13 +12,28 @@ import (
 
 var _ echo.Validator = &Validator{}
 
+// FieldNameTags 字段名取值的 tag 顺序，返回错误时使用
+var FieldNameTags = []string{"json", "query", "form", "param", "header"}
+
 // New New
 func New() *Validator {
 	v := &Validator{}
 	v.pool.New = func() interface{} {
-		return validator.New()
+		validate := validator.New()
+		validate.RegisterTagNameFunc(fieldName)
+		registerTranslations(validate)
+		for i := range configurators {
+			if err := configurators[i](validate); err != nil {
+				panic(err)
+			}
+		}
+		return validate
 	}
 
+	// 预先创建一个实例，翻译文案在此时注册，之后的实例只注册翻译方法，
+	// 配置有误时启动即 panic
+	v.pool.Put(v.pool.New())
+
 	return v
 }
 
@@ -36,3 +53,15 @@ func (p *Validator) Validate(i interface{}) error {
 	p.pool.Put(v)
 	return nil
 }
+
+// fieldName 按 FieldNameTags 取第一个有效的字段名，如 json:"-" header:"X-Token" 取 X-Token
+func fieldName(fld reflect.StructField) string {
+	for _, tag := range FieldNameTags {
+		name := strings.SplitN(fld.Tag.Get(tag), ",", 2)[0]
+		if len(name) > 0 && name != "-" {
+			return name
+		}
+	}
+
+	return fld.Name
+}
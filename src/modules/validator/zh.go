@@ -0,0 +1,106 @@
+package validator
+
+import (
+	"reflect"
+
+	ut "github.com/go-playground/universal-translator"
+
+	validator "gopkg.in/go-playground/validator.v9"
+)
+
+// zhTranslation 中文翻译，按字段类型区分 string/number/items 三种文案
+type zhTranslation struct {
+	tag    string
+	text   string // 不区分类型
+	str    string
+	number string
+	items  string
+}
+
+var zhTranslations = []zhTranslation{
+	{tag: "required", text: "{0}为必填字段"},
+	{tag: "len", str: "{0}长度必须是{1}个字符", number: "{0}必须等于{1}", items: "{0}必须包含{1}项"},
+	{tag: "min", str: "{0}长度不能少于{1}个字符", number: "{0}最小只能为{1}", items: "{0}至少包含{1}项"},
+	{tag: "max", str: "{0}长度不能超过{1}个字符", number: "{0}必须小于或等于{1}", items: "{0}最多只能包含{1}项"},
+	{tag: "eq", text: "{0}不等于{1}"},
+	{tag: "ne", text: "{0}不能等于{1}"},
+	{tag: "lt", str: "{0}长度必须小于{1}个字符", number: "{0}必须小于{1}", items: "{0}包含的项必须少于{1}项"},
+	{tag: "lte", str: "{0}长度不能超过{1}个字符", number: "{0}必须小于或等于{1}", items: "{0}最多只能包含{1}项"},
+	{tag: "gt", str: "{0}长度必须大于{1}个字符", number: "{0}必须大于{1}", items: "{0}包含的项必须多于{1}项"},
+	{tag: "gte", str: "{0}长度必须至少为{1}个字符", number: "{0}必须大于或等于{1}", items: "{0}至少包含{1}项"},
+	{tag: "eqfield", text: "{0}必须等于{1}"},
+	{tag: "nefield", text: "{0}不能等于{1}"},
+	{tag: "gtfield", text: "{0}必须大于{1}"},
+	{tag: "gtefield", text: "{0}必须大于或等于{1}"},
+	{tag: "ltfield", text: "{0}必须小于{1}"},
+	{tag: "ltefield", text: "{0}必须小于或等于{1}"},
+	{tag: "alpha", text: "{0}只能包含字母"},
+	{tag: "alphanum", text: "{0}只能包含字母和数字"},
+	{tag: "numeric", text: "{0}必须是一个有效的数值"},
+	{tag: "number", text: "{0}必须是一个有效的数字"},
+	{tag: "hexadecimal", text: "{0}必须是一个有效的十六进制"},
+	{tag: "email", text: "{0}必须是一个有效的邮箱"},
+	{tag: "url", text: "{0}必须是一个有效的URL"},
+	{tag: "uri", text: "{0}必须是一个有效的URI"},
+	{tag: "base64", text: "{0}必须是一个有效的Base64字符串"},
+	{tag: "contains", text: "{0}必须包含文本'{1}'"},
+	{tag: "containsany", text: "{0}必须包含至少一个以下字符'{1}'"},
+	{tag: "excludes", text: "{0}不能包含文本'{1}'"},
+	{tag: "excludesall", text: "{0}不能包含以下任何字符'{1}'"},
+	{tag: "uuid", text: "{0}必须是一个有效的UUID"},
+	{tag: "ascii", text: "{0}必须只包含ascii字符"},
+	{tag: "latitude", text: "{0}必须包含有效的纬度坐标"},
+	{tag: "longitude", text: "{0}必须包含有效的经度坐标"},
+	{tag: "ip", text: "{0}必须是一个有效的IP地址"},
+	{tag: "ipv4", text: "{0}必须是一个有效的IPv4地址"},
+	{tag: "ipv6", text: "{0}必须是一个有效的IPv6地址"},
+	{tag: "mac", text: "{0}必须是一个有效的MAC地址"},
+}
+
+// RegisterZhTranslations 注册中文翻译
+func RegisterZhTranslations(v *validator.Validate, trans ut.Translator) error {
+	for i := range zhTranslations {
+		t := zhTranslations[i]
+
+		err := v.RegisterTranslation(t.tag, trans, func(trans ut.Translator) error {
+			if len(t.text) > 0 {
+				return trans.Add(t.tag, t.text, false)
+			}
+			if err := trans.Add(t.tag+"-string", t.str, false); err != nil {
+				return err
+			}
+			if err := trans.Add(t.tag+"-number", t.number, false); err != nil {
+				return err
+			}
+			return trans.Add(t.tag+"-items", t.items, false)
+		}, zhTranslationFunc(t))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func zhTranslationFunc(t zhTranslation) validator.TranslationFunc {
+	return func(trans ut.Translator, fe validator.FieldError) string {
+		key := t.tag
+		if len(t.text) == 0 {
+			switch fe.Kind() {
+			case reflect.String:
+				key += "-string"
+			case reflect.Slice, reflect.Map, reflect.Array:
+				key += "-items"
+			default:
+				key += "-number"
+			}
+		}
+
+		msg, err := trans.T(key, fe.Field(), fe.Param())
+		if err != nil {
+			return fe.(error).Error()
+		}
+
+		return msg
+	}
+}
@@ -0,0 +1,144 @@
+package zerolog
+
+import (
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gocommon/zerolog"
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+)
+
+// AccessLogConfig 访问日志中间件配置
+type AccessLogConfig struct {
+	Skipper middleware.Skipper
+
+	// Logger 使用的日志名称，默认 access，输出方式在 ZeroLogs 中配置
+	Logger string
+
+	// SampleRate 2xx/3xx 每 SampleRate 条记录一条，<= 1 时全部记录
+	// 4xx/5xx 及慢请求总是记录
+	SampleRate int
+
+	// SlowThreshold 慢请求阈值，0 不判断
+	SlowThreshold time.Duration
+
+	// UserIDKey c.Get(UserIDKey) 获取用户 id，默认 user_id
+	UserIDKey string
+
+	// Headers 记录请求头，不在 RedactOption.Headers 中的值脱敏
+	Headers bool
+}
+
+// DefaultAccessLogConfig 默认配置
+var DefaultAccessLogConfig = AccessLogConfig{
+	Skipper:    middleware.DefaultSkipper,
+	Logger:     "access",
+	SampleRate: 1,
+	UserIDKey:  "user_id",
+}
+
+// AccessLog 使用默认配置的访问日志中间件
+func AccessLog() echo.MiddlewareFunc {
+	return AccessLogWithConfig(DefaultAccessLogConfig)
+}
+
+// AccessLogWithConfig 结构化访问日志
+// 5xx 记录为 error，4xx 及慢请求为 warn，其它为 info
+func AccessLogWithConfig(config AccessLogConfig) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = DefaultAccessLogConfig.Skipper
+	}
+	if len(config.Logger) == 0 {
+		config.Logger = DefaultAccessLogConfig.Logger
+	}
+	if len(config.UserIDKey) == 0 {
+		config.UserIDKey = DefaultAccessLogConfig.UserIDKey
+	}
+
+	var counter uint64
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			req := c.Request()
+			body := &countReader{ReadCloser: req.Body}
+			if req.Body != nil {
+				req.Body = body
+			}
+
+			start := time.Now()
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+			latency := time.Since(start)
+
+			res := c.Response()
+
+			slow := config.SlowThreshold > 0 && latency >= config.SlowThreshold
+
+			var level zerolog.Level
+			switch {
+			case res.Status >= http.StatusInternalServerError:
+				level = zerolog.ErrorLevel
+			case res.Status >= http.StatusBadRequest || slow:
+				level = zerolog.WarnLevel
+			default:
+				if config.SampleRate > 1 && atomic.AddUint64(&counter, 1)%uint64(config.SampleRate) != 1 {
+					return nil
+				}
+				level = zerolog.InfoLevel
+			}
+
+			id := req.Header.Get(echo.HeaderXRequestID)
+			if len(id) == 0 {
+				id = res.Header().Get(echo.HeaderXRequestID)
+			}
+
+			logger := Get(config.Logger)
+			ev := logger.WithLevel(level).
+				Str("request_id", id).
+				Str("remote_ip", c.RealIP()).
+				Str("host", req.Host).
+				Str("method", req.Method).
+				Str("uri", RedactURI(req.RequestURI)).
+				Str("route", c.Path()).
+				Str("user_agent", req.UserAgent()).
+				Int("status", res.Status).
+				Dur("latency", latency).
+				Int64("bytes_in", atomic.LoadInt64(&body.n)).
+				Int64("bytes_out", res.Size)
+
+			if config.Headers {
+				ev = ev.Interface("headers", RedactHeaders(req.Header))
+			}
+			if uid := c.Get(config.UserIDKey); uid != nil {
+				ev = ev.Interface("user_id", uid)
+			}
+			if slow {
+				ev = ev.Bool("slow", true)
+			}
+
+			ev.Msg("access")
+
+			return nil
+		}
+	}
+}
+
+// countReader 统计实际读取的请求体字节数，chunked 请求没有 Content-Length
+type countReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	atomic.AddInt64(&r.n, int64(n))
+	return n, err
+}
@@ -0,0 +1,53 @@
+package zerolog
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gocommon/zerolog"
+	"github.com/labstack/echo"
+)
+
+// captureLog 日志 name 输出到返回的 buffer
+func captureLog(name string) *bytes.Buffer {
+	buf := &bytes.Buffer{}
+	swapAll(map[string]zerolog.LevelWriter{
+		name: NewLevelWriter(buf, zerolog.DebugLevel),
+	}, nil, nil)
+	return buf
+}
+
+// chunkedReader 没有 Content-Length
+type chunkedReader struct{ io.Reader }
+
+func TestAccessLogBytesIn(t *testing.T) {
+	buf := captureLog("access")
+
+	e := echo.New()
+	e.Use(AccessLog())
+	e.POST("/", func(c echo.Context) error {
+		b, _ := ioutil.ReadAll(c.Request().Body)
+		return c.String(200, string(b))
+	})
+
+	req := httptest.NewRequest(echo.POST, "/", chunkedReader{strings.NewReader("hello world")})
+	req.ContentLength = -1
+	req.TransferEncoding = []string{"chunked"}
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	var line struct {
+		BytesIn  int64 `json:"bytes_in"`
+		BytesOut int64 `json:"bytes_out"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("%v: %s", err, buf)
+	}
+	if line.BytesIn != 11 || line.BytesOut != 11 {
+		t.Fatalf("bytes_in %d, bytes_out %d", line.BytesIn, line.BytesOut)
+	}
+}
@@ -0,0 +1,226 @@
+package zerolog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DedupeFields 汇总发送时比较的字段，这些字段相同的日志合并计数
+// 时间、请求 id、客户端 ip、请求路径等每次请求都不同的字段不比较，邮件中保留第一条的原文
+var DedupeFields = []string{"level", "message", "error", "caller"}
+
+// Entry 汇总发送的一条日志，DedupeFields 相同的合并计数
+type Entry struct {
+	Line  []byte
+	Count int
+	First time.Time
+	Last  time.Time
+}
+
+// Text 去掉换行的原文
+func (e *Entry) Text() string {
+	return string(bytes.TrimRight(e.Line, "\n"))
+}
+
+// JSON 原文是 json 时原样返回，否则返回 json 字符串
+func (e *Entry) JSON() string {
+	line := bytes.TrimRight(e.Line, "\n")
+	if json.Valid(line) {
+		return string(line)
+	}
+	b, _ := json.Marshal(string(line))
+	return string(b)
+}
+
+// batchOptions 汇总发送配置
+type batchOptions struct {
+	Interval  time.Duration // 汇总发送间隔
+	Limit     int           // 每 Per 最多发送次数，超过时累积到下一次
+	Per       time.Duration
+	Retries   int           // 发送失败重试次数
+	RetryWait time.Duration // 重试间隔，按次数递增
+	QueueSize int           // 队列及每次最多条目数，超过时丢弃并计数
+}
+
+// batchWriter 异步汇总发送
+// Write 只入队不阻塞，每 Interval 调用一次 send
+type batchWriter struct {
+	name string
+	opt  batchOptions
+	send func(entries []*Entry, dropped uint64) error
+
+	queue chan []byte
+	quit  chan struct{}
+	done  chan struct{}
+	once  sync.Once
+
+	dropped uint64
+
+	// 以下只在 loop 中使用
+	entries  []*Entry
+	index    map[string]*Entry
+	overflow uint64
+	sent     []time.Time
+}
+
+func newBatchWriter(name string, opt batchOptions, send func(entries []*Entry, dropped uint64) error) *batchWriter {
+	w := &batchWriter{
+		name:  name,
+		opt:   opt,
+		send:  send,
+		queue: make(chan []byte, opt.QueueSize),
+		quit:  make(chan struct{}),
+		done:  make(chan struct{}),
+		index: map[string]*Entry{},
+	}
+
+	go w.loop()
+
+	return w
+}
+
+// Write 入队，队列满时丢弃
+func (w *batchWriter) Write(p []byte) (int, error) {
+	// zerolog 会复用 p
+	line := make([]byte, len(p))
+	copy(line, p)
+
+	select {
+	case w.queue <- line:
+	default:
+		atomic.AddUint64(&w.dropped, 1)
+	}
+
+	return len(p), nil
+}
+
+// Close 发送队列中剩余的日志后返回
+func (w *batchWriter) Close() error {
+	w.once.Do(func() {
+		close(w.quit)
+		<-w.done
+	})
+	return nil
+}
+
+func (w *batchWriter) loop() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.opt.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case line := <-w.queue:
+			w.add(line)
+
+		case <-ticker.C:
+			w.flush(false)
+
+		case <-w.quit:
+			for {
+				select {
+				case line := <-w.queue:
+					w.add(line)
+					continue
+				default:
+				}
+				break
+			}
+			w.flush(true)
+			return
+		}
+	}
+}
+
+func (w *batchWriter) add(line []byte) {
+	now := time.Now()
+	key := dedupeKey(line)
+
+	if e, ok := w.index[key]; ok {
+		e.Count++
+		e.Last = now
+		return
+	}
+
+	if len(w.entries) >= w.opt.QueueSize {
+		w.overflow++
+		return
+	}
+
+	e := &Entry{Line: line, Count: 1, First: now, Last: now}
+	w.entries = append(w.entries, e)
+	w.index[key] = e
+}
+
+// flush 发送，超过频率上限时保留到下一次，force 时忽略上限
+func (w *batchWriter) flush(force bool) {
+	dropped := atomic.SwapUint64(&w.dropped, 0) + w.overflow
+	w.overflow = 0
+
+	if len(w.entries) == 0 && dropped == 0 {
+		return
+	}
+
+	now := time.Now()
+	sent := w.sent[:0]
+	for _, t := range w.sent {
+		if now.Sub(t) < w.opt.Per {
+			sent = append(sent, t)
+		}
+	}
+	w.sent = sent
+
+	if !force && len(w.sent) >= w.opt.Limit {
+		w.overflow = dropped
+		return
+	}
+
+	var err error
+	for i := 0; i <= w.opt.Retries; i++ {
+		if i > 0 {
+			time.Sleep(time.Duration(i) * w.opt.RetryWait)
+		}
+		if err = w.send(w.entries, dropped); err == nil {
+			break
+		}
+	}
+
+	w.sent = append(w.sent, now)
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "zerolog: %s send %d entries: %s\n", w.name, len(w.entries), err)
+	}
+
+	w.entries = w.entries[:0]
+	w.index = map[string]*Entry{}
+}
+
+// dedupeKey DedupeFields 的内容，不是 json 或没有这些字段时使用原文
+func dedupeKey(line []byte) string {
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(line, &m); err != nil {
+		return string(line)
+	}
+
+	key := map[string]interface{}{}
+	for _, k := range DedupeFields {
+		if v, has := m[k]; has {
+			key[k] = v
+		}
+	}
+	if len(key) == 0 {
+		return string(line)
+	}
+
+	b, err := json.Marshal(key)
+	if err != nil {
+		return string(line)
+	}
+	return string(b)
+}
@@ -0,0 +1,127 @@
+package zerolog
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo"
+)
+
+// DefaultMaxDumpSize 请求体、返回值最多记录的字节数，超过的部分不缓存
+var DefaultMaxDumpSize = 4096
+
+// DumpSkipTypes 不记录的返回类型，流式返回(SSE、NDJSON、CSV)在连接期间一直写入
+var DumpSkipTypes = []string{"text/event-stream", "application/x-ndjson", "text/csv"}
+
+// BodyDump 以 debug 级别记录脱敏后的请求体和返回值，需在 Middleware 之后、Gzip 之后使用
+// 只缓存前 DefaultMaxDumpSize 字节，压缩过的返回值不记录
+func BodyDump() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			max := DefaultMaxDumpSize
+			req := c.Request()
+
+			var (
+				reqBody      []byte
+				reqTruncated bool
+			)
+			if req.Body != nil {
+				reqBody, _ = ioutil.ReadAll(io.LimitReader(req.Body, int64(max)+1))
+				// 已读取的部分放回，后面的继续从原请求体读取
+				req.Body = dumpReadCloser{io.MultiReader(bytes.NewReader(reqBody), req.Body), req.Body}
+				if len(reqBody) > max {
+					reqBody, reqTruncated = reqBody[:max], true
+				}
+			}
+
+			res := c.Response()
+			dw := &dumpWriter{ResponseWriter: res.Writer, max: max}
+			res.Writer = dw
+			defer func() { res.Writer = dw.ResponseWriter }()
+
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			logger := FromEcho(c)
+			ev := logger.Debug().
+				Bytes("request_body", dumpBody(req.Header.Get(echo.HeaderContentType), reqBody, reqTruncated))
+			if dw.skip {
+				ev = ev.Str("response_body", "["+res.Header().Get(echo.HeaderContentType)+"]")
+			} else {
+				ev = ev.Bytes("response_body", dumpBody(res.Header().Get(echo.HeaderContentType), dw.buf.Bytes(), dw.truncated))
+			}
+			ev.Msg("body dump")
+
+			return nil
+		}
+	}
+}
+
+type dumpReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// dumpWriter 只缓存前 max 字节，流式及压缩过的返回值不缓存
+type dumpWriter struct {
+	http.ResponseWriter
+	buf       bytes.Buffer
+	max       int
+	checked   bool
+	skip      bool
+	truncated bool
+}
+
+func (w *dumpWriter) Write(b []byte) (int, error) {
+	if !w.checked {
+		w.checked = true
+		w.skip = skipDump(w.Header())
+	}
+	if !w.skip && !w.truncated {
+		n := len(b)
+		if rest := w.max - w.buf.Len(); n > rest {
+			n, w.truncated = rest, true
+		}
+		w.buf.Write(b[:n])
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *dumpWriter) Flush() {
+	w.ResponseWriter.(http.Flusher).Flush()
+}
+
+func (w *dumpWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+func (w *dumpWriter) CloseNotify() <-chan bool {
+	return w.ResponseWriter.(http.CloseNotifier).CloseNotify()
+}
+
+func skipDump(h http.Header) bool {
+	if enc := h.Get(echo.HeaderContentEncoding); len(enc) > 0 && enc != "identity" {
+		return true
+	}
+	ctype := h.Get(echo.HeaderContentType)
+	for _, t := range DumpSkipTypes {
+		if strings.HasPrefix(ctype, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// dumpBody 脱敏，截断的 json 只记录能完整解析的部分
+func dumpBody(contentType string, body []byte, truncated bool) []byte {
+	if !truncated {
+		return RedactBody(contentType, body)
+	}
+	return append(redactBodyPrefix(contentType, body), "..."...)
+}
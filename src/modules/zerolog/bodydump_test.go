@@ -0,0 +1,72 @@
+package zerolog
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+)
+
+type bodyDumpLine struct {
+	RequestBody  string `json:"request_body"`
+	ResponseBody string `json:"response_body"`
+}
+
+func serveBodyDump(t *testing.T, h echo.HandlerFunc, body string) (bodyDumpLine, *httptest.ResponseRecorder) {
+	buf := captureLog(DefaultName)
+
+	e := echo.New()
+	e.Use(BodyDump())
+	e.POST("/", h)
+
+	req := httptest.NewRequest(echo.POST, "/", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	var line bodyDumpLine
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("%v: %s", err, buf)
+	}
+	return line, rec
+}
+
+func TestBodyDumpTruncated(t *testing.T) {
+	defer func(n int) { DefaultMaxDumpSize = n }(DefaultMaxDumpSize)
+	DefaultMaxDumpSize = 40
+
+	body := `{"password":"p@ss","name":"` + strings.Repeat("a", 100) + `"}`
+	line, rec := serveBodyDump(t, func(c echo.Context) error {
+		b, _ := ioutil.ReadAll(c.Request().Body)
+		return c.JSONBlob(200, b)
+	}, body)
+
+	if rec.Body.String() != body {
+		t.Fatalf("handler body changed: %s", rec.Body)
+	}
+
+	want := `{"password":"******","name":...`
+	if line.RequestBody != want || line.ResponseBody != want {
+		t.Fatalf("got %q, %q", line.RequestBody, line.ResponseBody)
+	}
+}
+
+func TestBodyDumpSkipStream(t *testing.T) {
+	line, rec := serveBodyDump(t, func(c echo.Context) error {
+		c.Response().Header().Set(echo.HeaderContentType, "text/event-stream")
+		c.Response().WriteHeader(200)
+		c.Response().Write([]byte("data: hello\n\n"))
+		c.Response().Flush()
+		return nil
+	}, `{}`)
+
+	if rec.Body.String() != "data: hello\n\n" {
+		t.Fatalf("stream body %q", rec.Body)
+	}
+	if line.RequestBody != `{}` || line.ResponseBody != "[text/event-stream]" {
+		t.Fatalf("got %q, %q", line.RequestBody, line.ResponseBody)
+	}
+}
@@ -0,0 +1,108 @@
+package zerolog
+
+import (
+	"github.com/gocommon/zerolog"
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+	"github.com/rs/xid"
+)
+
+// ContextKey echo.Context 中保存请求日志的 key
+const ContextKey = "zerolog.logger"
+
+// MiddlewareConfig 请求日志中间件配置
+type MiddlewareConfig struct {
+	Skipper middleware.Skipper
+
+	// Logger 使用的日志名称，默认 default
+	Logger string
+
+	// RequestIDHeader 请求 id 头，请求中没有时生成并写入响应头，默认 X-Request-ID
+	RequestIDHeader string
+}
+
+// DefaultMiddlewareConfig 默认配置
+var DefaultMiddlewareConfig = MiddlewareConfig{
+	Skipper:         middleware.DefaultSkipper,
+	Logger:          "default",
+	RequestIDHeader: echo.HeaderXRequestID,
+}
+
+// Middleware 使用默认配置的请求日志中间件
+func Middleware() echo.MiddlewareFunc {
+	return MiddlewareWithConfig(DefaultMiddlewareConfig)
+}
+
+// MiddlewareWithConfig 为每个请求创建带 request_id, method, path, remote_ip, route 的子日志
+// 通过 FromEcho(c) 获取，也可以通过 zerolog.Ctx(c.Request().Context()) 或 hlog.FromRequest 获取
+func MiddlewareWithConfig(config MiddlewareConfig) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = DefaultMiddlewareConfig.Skipper
+	}
+	if len(config.Logger) == 0 {
+		config.Logger = DefaultMiddlewareConfig.Logger
+	}
+	if len(config.RequestIDHeader) == 0 {
+		config.RequestIDHeader = DefaultMiddlewareConfig.RequestIDHeader
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			req := c.Request()
+
+			id := req.Header.Get(config.RequestIDHeader)
+			if len(id) == 0 {
+				id = c.Response().Header().Get(config.RequestIDHeader)
+			}
+			if len(id) == 0 {
+				id = xid.New().String()
+			}
+			c.Response().Header().Set(config.RequestIDHeader, id)
+
+			logger := requestLogger(Get(config.Logger), c, id)
+
+			c.Set(ContextKey, logger)
+			c.SetRequest(req.WithContext(logger.WithContext(req.Context())))
+
+			return next(c)
+		}
+	}
+}
+
+// FromEcho 获取请求日志
+// 未使用 Middleware 时，使用 name 对应的日志(默认 default)并附加请求信息
+func FromEcho(c echo.Context, name ...string) zerolog.Logger {
+	if logger, ok := c.Get(ContextKey).(zerolog.Logger); ok {
+		return logger
+	}
+
+	id := c.Request().Header.Get(echo.HeaderXRequestID)
+	if len(id) == 0 {
+		id = c.Response().Header().Get(echo.HeaderXRequestID)
+	}
+
+	logger := requestLogger(Get(name...), c, id)
+	c.Set(ContextKey, logger)
+
+	return logger
+}
+
+func requestLogger(l zerolog.Logger, c echo.Context, id string) zerolog.Logger {
+	req := c.Request()
+
+	ctx := l.With()
+	if len(id) > 0 {
+		ctx = ctx.Str("request_id", id)
+	}
+
+	return ctx.
+		Str("method", req.Method).
+		Str("path", req.URL.Path).
+		Str("remote_ip", c.RealIP()).
+		Str("route", c.Path()).
+		Logger()
+}
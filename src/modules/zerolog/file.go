@@ -0,0 +1,432 @@
+package zerolog
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRotateTimeFormat 分割文件名默认时间格式，如 default.log.2006-01-02.001
+const DefaultRotateTimeFormat = "2006-01-02"
+
+// ReopenInterval 分割后重新打开文件失败时的重试间隔，期间写入的日志丢弃
+var ReopenInterval = 5 * time.Second
+
+// FileOptions 文件日志配置
+type FileOptions struct {
+	Filename string
+
+	// 分割条件，Rotate 为 false 时不分割
+	Rotate   bool
+	MaxLines int
+	MaxSize  int64
+	Daily    bool
+
+	// 分割文件保留规则，0 不限制
+	MaxDays      int   // 按修改时间删除
+	MaxBackups   int   // 最多保留数量
+	MaxTotalSize int64 // 最多保留总大小
+
+	// Compress 后台 gzip 压缩分割文件
+	Compress bool
+	// TimeFormat 分割文件名时间格式，默认 DefaultRotateTimeFormat
+	TimeFormat string
+}
+
+// FileWriter 可分割的文件日志，file 模式的输出，配置由 Option.RotateOptions 转换
+// 替代 rotatefile.Writer：分割条件、文件名与其一致，增加压缩和保留规则
+// 分割后的压缩、清理在后台进行，只处理已分割的文件，不影响写入
+type FileWriter struct {
+	opt FileOptions
+
+	mu     sync.Mutex
+	fd     *os.File
+	closed bool
+	size   int64
+	lines  int
+	// start 当前文件内容的开始时间，分割文件名使用它
+	start time.Time
+
+	// 打开失败时 retryAt 之前不再重试，返回 openErr
+	openErr error
+	retryAt time.Time
+
+	cleanup chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+// NewFileWriter 打开文件日志，启动时按保留规则清理一次
+func NewFileWriter(opt FileOptions) (*FileWriter, error) {
+	if len(opt.Filename) == 0 {
+		return nil, fmt.Errorf("zerolog: file writer requires filename")
+	}
+	if len(opt.TimeFormat) == 0 {
+		opt.TimeFormat = DefaultRotateTimeFormat
+	}
+
+	w := &FileWriter{
+		opt:     opt,
+		cleanup: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+
+	go w.janitor()
+	w.notify()
+
+	return w, nil
+}
+
+// Write io.Writer
+func (w *FileWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+
+	// 上次分割后重新打开失败
+	if w.fd == nil {
+		if err := w.reopen(); err != nil {
+			return 0, err
+		}
+	}
+
+	if w.shouldRotate() {
+		if err := w.rotate(); err != nil {
+			fmt.Fprintf(os.Stderr, "zerolog: rotate %q: %s\n", w.opt.Filename, err)
+			if w.fd == nil {
+				return 0, err
+			}
+		}
+	}
+
+	n, err := w.fd.Write(b)
+	w.size += int64(n)
+	w.lines++
+
+	return n, err
+}
+
+// Sync 刷新到磁盘
+func (w *FileWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.fd == nil {
+		return nil
+	}
+	return w.fd.Sync()
+}
+
+// Close 关闭文件，等待后台压缩清理结束
+func (w *FileWriter) Close() error {
+	var err error
+	w.once.Do(func() {
+		w.mu.Lock()
+		w.closed = true
+		if w.fd != nil {
+			err = w.fd.Close()
+			w.fd = nil
+		}
+		w.mu.Unlock()
+
+		close(w.cleanup)
+		<-w.done
+	})
+	return err
+}
+
+// reopen 每 ReopenInterval 最多重试一次打开文件，调用方持有锁
+func (w *FileWriter) reopen() error {
+	if time.Now().Before(w.retryAt) {
+		return w.openErr
+	}
+	return w.open()
+}
+
+// open 打开文件，失败时记录错误及下次重试时间
+func (w *FileWriter) open() error {
+	if err := w.openFile(); err != nil {
+		w.openErr = fmt.Errorf("zerolog: open %q: %s", w.opt.Filename, err)
+		w.retryAt = time.Now().Add(ReopenInterval)
+		return w.openErr
+	}
+	return nil
+}
+
+func (w *FileWriter) openFile() error {
+	if err := os.MkdirAll(filepath.Dir(w.opt.Filename), os.ModePerm); err != nil {
+		return err
+	}
+
+	fd, err := os.OpenFile(w.opt.Filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0660)
+	if err != nil {
+		return err
+	}
+
+	info, err := fd.Stat()
+	if err != nil {
+		fd.Close()
+		return err
+	}
+
+	w.fd = fd
+	w.size = info.Size()
+	w.lines = 0
+	// 已有内容的文件从最后修改时间算起，前一天的文件启动后第一次写入时按天分割
+	w.start = time.Now()
+	if w.size > 0 {
+		w.start = info.ModTime()
+	}
+
+	if w.size > 0 && w.opt.MaxLines > 0 {
+		w.lines, err = countLines(w.opt.Filename)
+	}
+
+	return err
+}
+
+func (w *FileWriter) shouldRotate() bool {
+	if !w.opt.Rotate {
+		return false
+	}
+
+	return (w.opt.MaxLines > 0 && w.lines >= w.opt.MaxLines) ||
+		(w.opt.MaxSize > 0 && w.size >= w.opt.MaxSize) ||
+		(w.opt.Daily && time.Now().Format("2006-01-02") != w.start.Format("2006-01-02"))
+}
+
+// rotate 重命名当前文件并重新打开，调用方持有锁
+func (w *FileWriter) rotate() error {
+	name, err := w.backupName()
+	if err != nil {
+		return err
+	}
+
+	w.fd.Close()
+	w.fd = nil
+
+	if err = os.Rename(w.opt.Filename, name); err != nil {
+		// 重命名失败时继续写原文件
+		if oerr := w.open(); oerr != nil {
+			return oerr
+		}
+		return err
+	}
+
+	if err = w.open(); err != nil {
+		return err
+	}
+
+	w.notify()
+
+	return nil
+}
+
+// backupName 分割文件名 filename.时间.序号，时间为文件内容的开始时间，按天分割时为前一天
+// 序号在已有文件之后，删除旧文件后不会重复使用，至少 3 位，超过 999 时继续递增为 1000，不会停止分割
+func (w *FileWriter) backupName() (string, error) {
+	stamp := w.start.Format(w.opt.TimeFormat)
+	base := filepath.Base(w.opt.Filename)
+
+	num := 0
+	matches, err := filepath.Glob(w.opt.Filename + "." + stamp + ".*")
+	if err != nil {
+		return "", err
+	}
+	for _, m := range matches {
+		if ts, n, ok := w.parseBackup(base, filepath.Base(m)); ok && ts == stamp && n > num {
+			num = n
+		}
+	}
+
+	return fmt.Sprintf("%s.%s.%03d", w.opt.Filename, stamp, num+1), nil
+}
+
+// parseBackup 解析分割文件名 base.时间.序号[.gz]，返回时间和序号
+// 其它同前缀的文件，如 app.log.bak 不是分割文件
+func (w *FileWriter) parseBackup(base, name string) (string, int, bool) {
+	if !strings.HasPrefix(name, base+".") {
+		return "", 0, false
+	}
+	rest := strings.TrimSuffix(name[len(base)+1:], ".gz")
+
+	i := strings.LastIndex(rest, ".")
+	if i < 0 {
+		return "", 0, false
+	}
+	stamp, seq := rest[:i], rest[i+1:]
+
+	if len(seq) < 3 || strings.TrimLeft(seq, "0123456789") != "" {
+		return "", 0, false
+	}
+	if _, err := time.Parse(w.opt.TimeFormat, stamp); err != nil {
+		return "", 0, false
+	}
+
+	n, err := strconv.Atoi(seq)
+	if err != nil {
+		return "", 0, false
+	}
+
+	return stamp, n, true
+}
+
+func (w *FileWriter) notify() {
+	select {
+	case w.cleanup <- struct{}{}:
+	default:
+	}
+}
+
+func (w *FileWriter) janitor() {
+	defer close(w.done)
+
+	for range w.cleanup {
+		if w.opt.Compress {
+			w.compressBackups()
+		}
+		w.removeBackups()
+	}
+}
+
+// backup 已分割的文件
+type backup struct {
+	path string
+	info os.FileInfo
+}
+
+// backups 已分割的文件，按修改时间从新到旧
+func (w *FileWriter) backups() []backup {
+	dir := filepath.Dir(w.opt.Filename)
+	base := filepath.Base(w.opt.Filename)
+
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	list := []backup{}
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() {
+			continue
+		}
+		if _, _, ok := w.parseBackup(base, name); !ok {
+			continue
+		}
+		list = append(list, backup{path: filepath.Join(dir, name), info: info})
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].info.ModTime().After(list[j].info.ModTime())
+	})
+
+	return list
+}
+
+func (w *FileWriter) compressBackups() {
+	for _, b := range w.backups() {
+		if strings.HasSuffix(b.path, ".gz") {
+			continue
+		}
+		if err := compressFile(b.path, b.info); err != nil {
+			fmt.Fprintf(os.Stderr, "zerolog: compress %q: %s\n", b.path, err)
+		}
+	}
+}
+
+func (w *FileWriter) removeBackups() {
+	var (
+		total  int64
+		expire time.Time
+	)
+	if w.opt.MaxDays > 0 {
+		expire = time.Now().Add(-time.Duration(w.opt.MaxDays) * 24 * time.Hour)
+	}
+
+	for i, b := range w.backups() {
+		total += b.info.Size()
+
+		if (w.opt.MaxBackups > 0 && i >= w.opt.MaxBackups) ||
+			(w.opt.MaxTotalSize > 0 && total > w.opt.MaxTotalSize) ||
+			(!expire.IsZero() && b.info.ModTime().Before(expire)) {
+			os.Remove(b.path)
+		}
+	}
+}
+
+// compressFile 压缩为 name.gz，先写临时文件，完成后再删除原文件
+func compressFile(name string, info os.FileInfo) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmp := name + ".gz.tmp"
+	dst, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode())
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err == nil {
+		err = gz.Close()
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	// 保留修改时间，按时间清理时不受压缩影响
+	os.Chtimes(tmp, info.ModTime(), info.ModTime())
+
+	if err = os.Rename(tmp, name+".gz"); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return os.Remove(name)
+}
+
+func countLines(name string) (int, error) {
+	fd, err := os.Open(name)
+	if err != nil {
+		return 0, err
+	}
+	defer fd.Close()
+
+	lines := 0
+	buf := make([]byte, 32*1024)
+	r := bufio.NewReader(fd)
+	for {
+		n, err := r.Read(buf)
+		lines += bytes.Count(buf[:n], []byte{'\n'})
+		if err == io.EOF {
+			return lines, nil
+		}
+		if err != nil {
+			return lines, err
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package zerolog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func touch(t *testing.T, name string, mtime time.Time) {
+	if err := ioutil.WriteFile(name, []byte("x\n"), 0660); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(name, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func listDir(t *testing.T, dir string) []string {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, info := range infos {
+		names = append(names, info.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestFileWriterRetentionKeepsOtherFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "zerolog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	now := time.Now()
+	for i, name := range []string{
+		"app.log.2026-10-03.001",
+		"app.log.2026-10-02.002.gz",
+		"app.log.2026-10-01.001",
+		// 不是分割文件
+		"app.log.bak",
+		"app.log.error",
+		"app.log.2026-10-01.tmp",
+		"default.log.error",
+	} {
+		touch(t, filepath.Join(dir, name), now.Add(-time.Duration(i+1)*time.Hour))
+	}
+
+	w, err := NewFileWriter(FileOptions{Filename: filepath.Join(dir, "app.log"), MaxBackups: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	want := []string{
+		"app.log",
+		"app.log.2026-10-01.tmp",
+		"app.log.2026-10-03.001",
+		"app.log.bak",
+		"app.log.error",
+		"default.log.error",
+	}
+	if got := listDir(t, dir); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestFileWriterMaxDays(t *testing.T) {
+	dir, err := ioutil.TempDir("", "zerolog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	now := time.Now()
+	touch(t, filepath.Join(dir, "app.log.2026-10-17.001"), now.Add(-time.Hour))
+	touch(t, filepath.Join(dir, "app.log.2026-10-01.001.gz"), now.Add(-10*24*time.Hour))
+
+	w, err := NewFileWriter(FileOptions{Filename: filepath.Join(dir, "app.log"), MaxDays: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	want := []string{"app.log", "app.log.2026-10-17.001"}
+	if got := listDir(t, dir); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestFileWriterRotateCompress(t *testing.T) {
+	dir, err := ioutil.TempDir("", "zerolog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	w, err := NewFileWriter(FileOptions{
+		Filename: filepath.Join(dir, "app.log"),
+		Rotate:   true,
+		MaxLines: 1,
+		Compress: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := w.Write([]byte("line\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w.Close()
+
+	stamp := time.Now().Format(DefaultRotateTimeFormat)
+	want := []string{
+		"app.log",
+		"app.log." + stamp + ".001.gz",
+		"app.log." + stamp + ".002.gz",
+	}
+	if got := listDir(t, dir); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestFileWriterBackupNameAfter999(t *testing.T) {
+	dir, err := ioutil.TempDir("", "zerolog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "app.log")
+	stamp := time.Now().Format(DefaultRotateTimeFormat)
+	touch(t, name+"."+stamp+".999.gz", time.Now())
+
+	w := &FileWriter{opt: FileOptions{Filename: name, TimeFormat: DefaultRotateTimeFormat}, start: time.Now()}
+	backup, err := w.backupName()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if backup != name+"."+stamp+".1000" {
+		t.Fatalf("got %s", backup)
+	}
+}
+
+func TestRotateOptionsDefaults(t *testing.T) {
+	off := false
+	fopt := Option{FileName: "app.log", DailyRotate: &off, MaxDays: -1, MaxTotalSizeShift: 20}.RotateOptions()
+
+	if !fopt.Rotate || fopt.Daily || fopt.MaxLines != DefaultMaxLines || fopt.MaxSize != 1<<DefaultMaxSizeShift {
+		t.Fatalf("unexpected %+v", fopt)
+	}
+	if fopt.MaxDays != 0 || fopt.MaxTotalSize != 1<<20 {
+		t.Fatalf("retention %+v", fopt)
+	}
+}
+
+func TestFileWriterDailyBackupUsesFileDay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "zerolog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "app.log")
+	yesterday := time.Now().AddDate(0, 0, -1)
+	touch(t, name, yesterday)
+
+	w, err := NewFileWriter(FileOptions{Filename: name, Rotate: true, Daily: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("today\n")); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	want := []string{"app.log", "app.log." + yesterday.Format(DefaultRotateTimeFormat) + ".001"}
+	if got := listDir(t, dir); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestFileWriterReopenAfterFailedRotate(t *testing.T) {
+	defer func(d time.Duration) { ReopenInterval = d }(ReopenInterval)
+	ReopenInterval = 50 * time.Millisecond
+
+	dir, err := ioutil.TempDir("", "zerolog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	logDir := filepath.Join(dir, "log")
+	w, err := NewFileWriter(FileOptions{Filename: filepath.Join(logDir, "app.log"), Rotate: true, MaxLines: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	if _, err := w.Write([]byte("a\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	// 目录被文件占用，分割后无法重新打开
+	os.RemoveAll(logDir)
+	touch(t, logDir, time.Now())
+	if _, err := w.Write([]byte("b\n")); err == nil {
+		t.Fatal("want error when reopen fails")
+	}
+
+	os.Remove(logDir)
+	if _, err := w.Write([]byte("c\n")); err == nil {
+		t.Fatal("want error before ReopenInterval")
+	}
+
+	time.Sleep(2 * ReopenInterval)
+	if _, err := w.Write([]byte("d\n")); err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if b, _ := ioutil.ReadFile(filepath.Join(logDir, "app.log")); string(b) != "d\n" {
+		t.Fatalf("got %q", b)
+	}
+}
@@ -0,0 +1,142 @@
+//go:build !windows
+// +build !windows
+
+package zerolog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gocommon/zerolog"
+)
+
+// DefaultJournalSocket journald 默认 socket
+const DefaultJournalSocket = "/run/systemd/journal/socket"
+
+// journalPriorities zerolog 级别对应的 syslog 优先级
+var journalPriorities = map[zerolog.Level]int{
+	zerolog.DebugLevel: 7,
+	zerolog.InfoLevel:  6,
+	zerolog.WarnLevel:  4,
+	zerolog.ErrorLevel: 3,
+	zerolog.FatalLevel: 2,
+	zerolog.PanicLevel: 0,
+}
+
+// JournalWriter 通过 journald 原生协议写入，json 字段转为大写的 journal 字段
+type JournalWriter struct {
+	conn *net.UnixConn
+	addr *net.UnixAddr
+	tag  string
+}
+
+// NewJournalWriter 连接 journald，socket 为空时使用 DefaultJournalSocket，tag 默认程序名
+func NewJournalWriter(socket, tag string) (*JournalWriter, error) {
+	if len(socket) == 0 {
+		socket = DefaultJournalSocket
+	}
+	if len(tag) == 0 {
+		tag = filepath.Base(os.Args[0])
+	}
+
+	if _, err := os.Stat(socket); err != nil {
+		return nil, fmt.Errorf("zerolog: journald not available: %s", err)
+	}
+
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+
+	return &JournalWriter{
+		conn: conn,
+		addr: &net.UnixAddr{Name: socket, Net: "unixgram"},
+		tag:  tag,
+	}, nil
+}
+
+// Write 没有级别时按 info 写入
+func (w *JournalWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.InfoLevel, p)
+}
+
+// WriteLevel zerolog.LevelWriter
+func (w *JournalWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	var buf bytes.Buffer
+
+	line := bytes.TrimRight(p, "\n")
+	msg := string(line)
+
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(line, &fields); err == nil {
+		if m, ok := fields[zerolog.MessageFieldName].(string); ok {
+			msg = m
+		}
+		for k, v := range fields {
+			if k == zerolog.MessageFieldName {
+				continue
+			}
+			key := journalKey(k)
+			if len(key) == 0 {
+				continue
+			}
+			writeJournalField(&buf, key, journalValue(v))
+		}
+	}
+
+	writeJournalField(&buf, "MESSAGE", msg)
+	writeJournalField(&buf, "PRIORITY", strconv.Itoa(journalPriorities[level]))
+	writeJournalField(&buf, "SYSLOG_IDENTIFIER", w.tag)
+
+	if _, err := w.conn.WriteToUnix(buf.Bytes(), w.addr); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Close io.Closer
+func (w *JournalWriter) Close() error {
+	return w.conn.Close()
+}
+
+// writeJournalField 值中有换行时使用二进制格式
+func writeJournalField(buf *bytes.Buffer, key, value string) {
+	if !strings.Contains(value, "\n") {
+		buf.WriteString(key + "=" + value + "\n")
+		return
+	}
+
+	buf.WriteString(key + "\n")
+	binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value + "\n")
+}
+
+// journalKey 字段名只能是大写字母、数字、下划线，不能以下划线开头
+func journalKey(k string) string {
+	key := []byte(strings.ToUpper(k))
+	for i, c := range key {
+		if !(c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			key[i] = '_'
+		}
+	}
+	return strings.TrimLeft(string(key), "_0123456789")
+}
+
+func journalValue(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case nil:
+		return ""
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
@@ -0,0 +1,144 @@
+package zerolog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gocommon/zerolog"
+)
+
+// LevelInfo 日志及各输出的当前级别
+type LevelInfo struct {
+	Logger   string            `json:"logger"`
+	Level    string            `json:"level"`
+	Sinks    map[string]string `json:"sinks"`
+	RevertAt map[string]string `json:"revert_at,omitempty"` // 临时修改的自动恢复时间，key 为空表示日志本身
+}
+
+// loggerLevels 日志及各输出的级别，日志级别在所有输出之前过滤
+type loggerLevels struct {
+	level *levelWriter
+	sinks map[string]*levelWriter
+
+	reverts map[string]*revert
+}
+
+// revert 临时修改，到期恢复为 level
+type revert struct {
+	level zerolog.Level
+	at    time.Time
+	timer *time.Timer
+}
+
+var (
+	levelsMu sync.Mutex
+	levels   = map[string]*loggerLevels{}
+)
+
+// ParseLevel 解析级别，不支持时返回错误
+func ParseLevel(str string) (zerolog.Level, error) {
+	if l, has := Levels[strings.ToLower(str)]; has {
+		return l, nil
+	}
+	return zerolog.DebugLevel, fmt.Errorf("zerolog: unknown level %q", str)
+}
+
+func newLoggerLevels(level *levelWriter, sinks map[string]*levelWriter) *loggerLevels {
+	return &loggerLevels{
+		level:   level,
+		sinks:   sinks,
+		reverts: map[string]*revert{},
+	}
+}
+
+// replaceLevels InitLog 后替换所有日志的级别，取消未到期的恢复
+func replaceLevels(all map[string]*loggerLevels) {
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+
+	for _, ll := range levels {
+		for _, r := range ll.reverts {
+			r.timer.Stop()
+		}
+	}
+
+	levels = all
+}
+
+// GetLevels 所有日志的当前级别，按名称排序
+func GetLevels() []LevelInfo {
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+
+	list := make([]LevelInfo, 0, len(levels))
+	for name, ll := range levels {
+		info := LevelInfo{
+			Logger: name,
+			Level:  ll.level.Level().String(),
+			Sinks:  make(map[string]string, len(ll.sinks)),
+		}
+		for k, w := range ll.sinks {
+			info.Sinks[k] = w.Level().String()
+		}
+		if len(ll.reverts) > 0 {
+			info.RevertAt = make(map[string]string, len(ll.reverts))
+			for k, r := range ll.reverts {
+				info.RevertAt[k] = r.at.Format(time.RFC3339)
+			}
+		}
+		list = append(list, info)
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Logger < list[j].Logger })
+
+	return list
+}
+
+// SetLevel 修改日志级别，sink 为空时修改日志本身，否则修改对应输出
+// ttl > 0 时到期恢复为修改前的级别，重复修改时恢复为第一次修改前的级别
+func SetLevel(name, sink string, level zerolog.Level, ttl time.Duration) error {
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+
+	ll, has := levels[name]
+	if !has {
+		return fmt.Errorf("zerolog: logger %q not found", name)
+	}
+
+	w := ll.level
+	if len(sink) > 0 {
+		if w, has = ll.sinks[sink]; !has {
+			return fmt.Errorf("zerolog: sink %q of logger %q not found", sink, name)
+		}
+	}
+
+	origin := w.Level()
+	if r, has := ll.reverts[sink]; has {
+		r.timer.Stop()
+		origin = r.level
+		delete(ll.reverts, sink)
+	}
+
+	w.SetLevel(level)
+
+	if ttl > 0 {
+		r := &revert{level: origin, at: time.Now().Add(ttl)}
+		r.timer = time.AfterFunc(ttl, func() {
+			levelsMu.Lock()
+			defer levelsMu.Unlock()
+
+			// 已被再次修改或重新初始化
+			if ll.reverts[sink] != r {
+				return
+			}
+			delete(ll.reverts, sink)
+			w.SetLevel(r.level)
+		})
+		ll.reverts[sink] = r
+	}
+
+	return nil
+}
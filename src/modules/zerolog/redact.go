@@ -0,0 +1,455 @@
+package zerolog
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/labstack/echo"
+)
+
+// Redacted 替换敏感值
+const Redacted = "******"
+
+// SecretTag 结构体字段标记为敏感，如 Passwd string `secret:"true"`
+const SecretTag = "secret"
+
+// RedactOption 脱敏配置
+type RedactOption struct {
+	// Keys 敏感字段名，不区分大小写，忽略 _ 和 -，支持 * 通配，如 *token 匹配 access_token、AccessToken
+	// 整个字段名匹配时才脱敏，tokens_used 不匹配 *token
+	Keys []string
+	// Headers 可以记录原值的请求头，其它请求头的值脱敏
+	Headers []string
+}
+
+// DefaultRedactOption 默认配置
+var DefaultRedactOption = RedactOption{
+	Keys: []string{
+		"*passwd", "*password", "*secret", "secretkey", "*token", "authorization",
+		"cookie", "*apikey", "privatekey", "accesskey",
+	},
+	Headers: []string{
+		echo.HeaderAccept, echo.HeaderContentType, echo.HeaderContentLength, echo.HeaderAcceptEncoding,
+		"Accept-Language", "User-Agent", "Referer", echo.HeaderOrigin,
+		echo.HeaderXRequestID, echo.HeaderXForwardedFor, echo.HeaderXRealIP,
+	},
+}
+
+var (
+	redactMu      sync.RWMutex
+	redactKeys    []string
+	redactHeaders map[string]bool
+)
+
+func init() {
+	InitRedact(DefaultRedactOption)
+}
+
+// InitRedact 设置脱敏配置，未设置的项使用默认值
+func InitRedact(opt RedactOption) {
+	if len(opt.Keys) == 0 {
+		opt.Keys = DefaultRedactOption.Keys
+	}
+	if len(opt.Headers) == 0 {
+		opt.Headers = DefaultRedactOption.Headers
+	}
+
+	keys := make([]string, 0, len(opt.Keys))
+	for _, k := range opt.Keys {
+		k = normalizeKey(strings.TrimSpace(k))
+		if _, err := path.Match(k, ""); err != nil || len(k) == 0 {
+			continue
+		}
+		keys = append(keys, k)
+	}
+
+	headers := make(map[string]bool, len(opt.Headers))
+	for _, h := range opt.Headers {
+		headers[http.CanonicalHeaderKey(h)] = true
+	}
+
+	redactMu.Lock()
+	redactKeys, redactHeaders = keys, headers
+	redactMu.Unlock()
+}
+
+// IsSecretKey 字段名是否敏感，见 RedactOption.Keys
+func IsSecretKey(key string) bool {
+	key = normalizeKey(key)
+
+	redactMu.RLock()
+	defer redactMu.RUnlock()
+
+	for _, k := range redactKeys {
+		if ok, _ := path.Match(k, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+var keyReplacer = strings.NewReplacer("_", "", "-", "")
+
+// normalizeKey 小写并去掉 _ 和 -，api_key、Api-Key、APIKey 相同
+func normalizeKey(key string) string {
+	return keyReplacer.Replace(strings.ToLower(key))
+}
+
+// Redact 返回脱敏后的副本，用于记录配置、请求参数等
+// 结构体转为 map，字段名与 json 一致
+// 带 secret tag 的值替换为 Redacted，字段名敏感时只替换字符串，数字等保持原值
+func Redact(v interface{}) interface{} {
+	return redactValue(reflect.ValueOf(v))
+}
+
+func redactValue(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+
+	// 实现了 json.Marshaler 的类型保持原样，如 time.Time
+	if v.CanInterface() {
+		if _, ok := v.Interface().(json.Marshaler); ok {
+			return v.Interface()
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return redactValue(v.Elem())
+
+	case reflect.Struct:
+		m := make(map[string]interface{}, v.NumField())
+		redactStruct(v, m)
+		return m
+
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		m := make(map[string]interface{}, v.Len())
+		for _, k := range v.MapKeys() {
+			key := mapKey(k)
+			if IsSecretKey(key) {
+				m[key] = redactSecret(v.MapIndex(k))
+				continue
+			}
+			m[key] = redactValue(v.MapIndex(k))
+		}
+		return m
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Interface()
+		}
+		list := make([]interface{}, v.Len())
+		for i := range list {
+			list[i] = redactValue(v.Index(i))
+		}
+		return list
+	}
+
+	if v.CanInterface() {
+		return v.Interface()
+	}
+	return nil
+}
+
+func redactStruct(v reflect.Value, m map[string]interface{}) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fv := v.Field(i)
+
+		if f.Anonymous && reflect.Indirect(fv).Kind() == reflect.Struct && len(f.Tag.Get("json")) == 0 {
+			if fv.Kind() == reflect.Ptr && fv.IsNil() {
+				continue
+			}
+			redactStruct(reflect.Indirect(fv), m)
+			continue
+		}
+		if len(f.PkgPath) > 0 {
+			continue
+		}
+
+		name := f.Name
+		if tag := f.Tag.Get("json"); len(tag) > 0 {
+			tag = strings.Split(tag, ",")[0]
+			if tag == "-" {
+				continue
+			}
+			if len(tag) > 0 {
+				name = tag
+			}
+		}
+
+		if f.Tag.Get(SecretTag) == "true" {
+			if !isZero(fv) {
+				m[name] = Redacted
+			} else {
+				m[name] = redactValue(fv)
+			}
+			continue
+		}
+		if IsSecretKey(name) {
+			m[name] = redactSecret(fv)
+			continue
+		}
+
+		m[name] = redactValue(fv)
+	}
+}
+
+// redactSecret 敏感字段的值，字符串替换为 Redacted，切片和 map 中的字符串同样替换，其它类型保持原值
+func redactSecret(v reflect.Value) interface{} {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		if v.Len() == 0 {
+			return ""
+		}
+		return Redacted
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			if v.Len() == 0 {
+				return v.Interface()
+			}
+			return Redacted
+		}
+		list := make([]interface{}, v.Len())
+		for i := range list {
+			list[i] = redactSecret(v.Index(i))
+		}
+		return list
+
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		m := make(map[string]interface{}, v.Len())
+		for _, k := range v.MapKeys() {
+			m[mapKey(k)] = redactSecret(v.MapIndex(k))
+		}
+		return m
+
+	case reflect.Struct:
+		m, ok := redactValue(v).(map[string]interface{})
+		if !ok {
+			return redactValue(v)
+		}
+		for k := range m {
+			m[k] = redactSecret(reflect.ValueOf(m[k]))
+		}
+		return m
+	}
+
+	return redactValue(v)
+}
+
+// RedactHeaders 请求头，不在 Headers 中的值脱敏
+func RedactHeaders(h http.Header) map[string]string {
+	redactMu.RLock()
+	defer redactMu.RUnlock()
+
+	m := make(map[string]string, len(h))
+	for k, v := range h {
+		k = http.CanonicalHeaderKey(k)
+		if redactHeaders[k] {
+			m[k] = strings.Join(v, ", ")
+		} else {
+			m[k] = Redacted
+		}
+	}
+	return m
+}
+
+// RedactURI 请求地址中敏感的 query 参数脱敏，保持参数顺序
+func RedactURI(uri string) string {
+	i := strings.Index(uri, "?")
+	if i < 0 {
+		return uri
+	}
+
+	query, changed := redactQuery(uri[i+1:])
+	if !changed {
+		return uri
+	}
+
+	return uri[:i+1] + query
+}
+
+// redactQuery 敏感参数脱敏，保持参数顺序，返回是否有修改
+func redactQuery(query string) (string, bool) {
+	pairs := strings.Split(query, "&")
+	changed := false
+	for j, pair := range pairs {
+		k := pair
+		if n := strings.Index(pair, "="); n >= 0 {
+			k = pair[:n]
+		}
+		if key, err := url.QueryUnescape(k); err == nil && IsSecretKey(key) {
+			pairs[j] = k + "=" + Redacted
+			changed = true
+		}
+	}
+	if !changed {
+		return query, false
+	}
+
+	return strings.Join(pairs, "&"), true
+}
+
+// RedactBody 请求体或返回值脱敏，支持 json 和 form，其它类型原样返回
+func RedactBody(contentType string, body []byte) []byte {
+	switch {
+	case strings.HasPrefix(contentType, echo.MIMEApplicationJSON):
+		return RedactJSON(body)
+
+	case strings.HasPrefix(contentType, echo.MIMEApplicationForm):
+		if q, changed := redactQuery(string(body)); changed {
+			return []byte(q)
+		}
+		return body
+	}
+
+	return body
+}
+
+// redactBodyPrefix 截断的请求体或返回值脱敏
+// json 只保留能完整解析的部分，其中的敏感值已替换，不完整的值不记录
+func redactBodyPrefix(contentType string, body []byte) []byte {
+	switch {
+	case strings.HasPrefix(contentType, echo.MIMEApplicationJSON):
+		d := json.NewDecoder(bytes.NewReader(body))
+		d.UseNumber()
+
+		var buf bytes.Buffer
+		redactJSONValue(d, &buf, false)
+		return buf.Bytes()
+
+	case strings.HasPrefix(contentType, echo.MIMEApplicationForm):
+		q, _ := redactQuery(string(body))
+		return []byte(q)
+	}
+
+	return body
+}
+
+// RedactJSON json 中敏感字段的字符串值脱敏，保持字段顺序和其它值，不是 json 时原样返回
+func RedactJSON(b []byte) []byte {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+
+	var buf bytes.Buffer
+	changed, err := redactJSONValue(d, &buf, false)
+	if err != nil || !changed {
+		return b
+	}
+
+	return buf.Bytes()
+}
+
+// redactJSONValue 逐个 token 读取一个值写入 buf，secret 为 true 时字符串替换为 Redacted，返回是否有修改
+func redactJSONValue(d *json.Decoder, buf *bytes.Buffer, secret bool) (bool, error) {
+	tok, err := d.Token()
+	if err != nil {
+		return false, err
+	}
+
+	changed := false
+	switch t := tok.(type) {
+	case json.Delim:
+		buf.WriteString(t.String())
+		for i := 0; d.More(); i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			s := secret
+			if t == '{' {
+				key, err := d.Token()
+				if err != nil {
+					return false, err
+				}
+				k, _ := key.(string)
+				writeJSONString(buf, k)
+				buf.WriteByte(':')
+				s = secret || IsSecretKey(k)
+			}
+
+			c, err := redactJSONValue(d, buf, s)
+			if err != nil {
+				return false, err
+			}
+			changed = changed || c
+		}
+		end, err := d.Token()
+		if err != nil {
+			return false, err
+		}
+		buf.WriteString(end.(json.Delim).String())
+
+	case string:
+		if secret && len(t) > 0 && t != Redacted {
+			t, changed = Redacted, true
+		}
+		writeJSONString(buf, t)
+
+	case json.Number:
+		buf.WriteString(t.String())
+
+	case bool:
+		if t {
+			buf.WriteString("true")
+		} else {
+			buf.WriteString("false")
+		}
+
+	case nil:
+		buf.WriteString("null")
+	}
+
+	return changed, nil
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) {
+	b, _ := json.Marshal(s)
+	buf.Write(b)
+}
+
+func mapKey(k reflect.Value) string {
+	if k.Kind() == reflect.String {
+		return k.String()
+	}
+	b, _ := json.Marshal(k.Interface())
+	return strings.Trim(string(b), `"`)
+}
+
+func isZero(v reflect.Value) bool {
+	return !v.IsValid() || v.IsZero()
+}
@@ -0,0 +1,95 @@
+package zerolog
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo"
+)
+
+func TestIsSecretKey(t *testing.T) {
+	cases := map[string]bool{
+		"password":      true,
+		"Passwd":        true,
+		"db_password":   true,
+		"access_token":  true,
+		"AccessToken":   true,
+		"X-Api-Key":     true,
+		"api_key":       true,
+		"Authorization": true,
+		"client_secret": true,
+		"tokens_used":   false,
+		"Tokens":        false,
+		"secretary":     false,
+		"name":          false,
+	}
+
+	for key, want := range cases {
+		if got := IsSecretKey(key); got != want {
+			t.Errorf("%s: got %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestInitRedactCustomKeys(t *testing.T) {
+	defer InitRedact(DefaultRedactOption)
+
+	InitRedact(RedactOption{Keys: []string{"pin", "[bad"}})
+	if !IsSecretKey("PIN") || IsSecretKey("password") || IsSecretKey("[bad") {
+		t.Fatal("custom keys not applied")
+	}
+}
+
+func TestRedactKeepsNonString(t *testing.T) {
+	type usage struct {
+		Tokens     int    `json:"tokens"`
+		TokensUsed int    `json:"tokens_used"`
+		Token      string `json:"token"`
+		MaxToken   int    `json:"max_token"`
+		Passwd     string `secret:"true"`
+	}
+
+	m := Redact(usage{Tokens: 5, TokensUsed: 6, Token: "abc", MaxToken: 7, Passwd: "x"}).(map[string]interface{})
+	if m["tokens"] != 5 || m["tokens_used"] != 6 || m["max_token"] != 7 {
+		t.Errorf("non-string values changed: %v", m)
+	}
+	if m["token"] != Redacted || m["Passwd"] != Redacted {
+		t.Errorf("secrets not redacted: %v", m)
+	}
+}
+
+func TestRedactJSONKeepsOrder(t *testing.T) {
+	in := `{"time":"2026-10-18T10:00:00Z","level":"info","token":"abc","tokens_used":5,"nested":{"password":"p","n":1.50},"list":[{"api_key":"k"}],"ok":true,"nil":null}`
+	want := `{"time":"2026-10-18T10:00:00Z","level":"info","token":"******","tokens_used":5,"nested":{"password":"******","n":1.50},"list":[{"api_key":"******"}],"ok":true,"nil":null}`
+
+	if got := string(RedactJSON([]byte(in))); got != want {
+		t.Fatalf("got  %s\nwant %s", got, want)
+	}
+
+	// 没有敏感字段时原样返回
+	plain := `{"b":1, "a":"x"}`
+	if got := string(RedactJSON([]byte(plain))); got != plain {
+		t.Fatalf("got %s", got)
+	}
+}
+
+func TestRedactBodyForm(t *testing.T) {
+	got := string(RedactBody(echo.MIMEApplicationForm, []byte("user=a&password=p&tokens_used=5")))
+	if got != "user=a&password=******&tokens_used=5" {
+		t.Fatalf("got %s", got)
+	}
+}
+
+func TestRedactURIAndHeaders(t *testing.T) {
+	if got := RedactURI("/login?user=a&access_token=t&page=2"); got != "/login?user=a&access_token=******&page=2" {
+		t.Errorf("uri %s", got)
+	}
+
+	h := http.Header{}
+	h.Set("Authorization", "Bearer x")
+	h.Set("User-Agent", "curl")
+	m := RedactHeaders(h)
+	if m["Authorization"] != Redacted || m["User-Agent"] != "curl" {
+		t.Errorf("headers %v", m)
+	}
+}
@@ -0,0 +1,190 @@
+package zerolog
+
+import (
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gocommon/zerolog"
+)
+
+// DefaultName 默认日志名称，未配置的日志使用它的输出
+const DefaultName = "default"
+
+// swapWriter 可替换的输出
+// Logger 是值类型，已获取的 Logger(包括 InitLog 之前获取的)在重新初始化后写入新的输出
+type swapWriter struct {
+	v atomic.Value
+}
+
+type writerBox struct {
+	w zerolog.LevelWriter
+}
+
+func newSwapWriter(w zerolog.LevelWriter) *swapWriter {
+	s := &swapWriter{}
+	s.swap(w)
+	return s
+}
+
+func (s *swapWriter) swap(w zerolog.LevelWriter) {
+	s.v.Store(writerBox{w})
+}
+
+func (s *swapWriter) load() zerolog.LevelWriter {
+	return s.v.Load().(writerBox).w
+}
+
+// Write io.Writer
+func (s *swapWriter) Write(p []byte) (int, error) {
+	return s.load().Write(p)
+}
+
+// WriteLevel zerolog.LevelWriter
+func (s *swapWriter) WriteLevel(l zerolog.Level, p []byte) (int, error) {
+	return s.load().WriteLevel(l, p)
+}
+
+// entry 一个名称的日志
+type entry struct {
+	w      *swapWriter
+	logger atomic.Value // zerolog.Logger
+}
+
+func (e *entry) build(name string, withs []WithContext, fallback bool) {
+	c := zerolog.New(e.w).With()
+	for i := range withs {
+		c = withs[i](c)
+	}
+	if fallback {
+		c = c.Str("logger", name)
+	}
+	e.logger.Store(c.Logger())
+}
+
+func (e *entry) get() zerolog.Logger {
+	return e.logger.Load().(zerolog.Logger)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]*entry{}
+
+	// initialized 是否已 InitLog，之后获取未配置的日志时警告
+	initialized bool
+	currWiths   = []WithContext{Timestamp()}
+	warned      = map[string]bool{}
+
+	// closers 当前输出中需要关闭的
+	closers []io.Closer
+)
+
+// stderrWriter InitLog 之前及未配置 default 时的输出
+func stderrWriter() zerolog.LevelWriter {
+	return newLevelWriter(os.Stderr, zerolog.DebugLevel)
+}
+
+func init() {
+	e := &entry{w: newSwapWriter(stderrWriter())}
+	e.build(DefaultName, currWiths, false)
+	registry[DefaultName] = e
+}
+
+// Get 获取日志，默认 default
+// 没有配置的名称使用 default 的输出并带 logger 字段，InitLog 之后第一次获取时警告
+// InitLog 之前也可以获取，初始化后写入配置的输出，上下文字段保留获取时的
+func Get(name ...string) zerolog.Logger {
+	cname := DefaultName
+	if len(name) > 0 && len(name[0]) > 0 {
+		cname = name[0]
+	}
+
+	registryMu.RLock()
+	e, has := registry[cname]
+	registryMu.RUnlock()
+	if has {
+		return e.get()
+	}
+
+	registryMu.Lock()
+	e, has = registry[cname]
+	if !has {
+		e = &entry{w: newSwapWriter(registry[DefaultName].w)}
+		e.build(cname, currWiths, true)
+		registry[cname] = e
+	}
+	warn := initialized && !warned[cname]
+	if warn {
+		warned[cname] = true
+	}
+	registryMu.Unlock()
+
+	if warn {
+		warnFallback(cname)
+	}
+
+	return e.get()
+}
+
+// Names 已配置的日志名称
+func Names() []string {
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+
+	names := make([]string, 0, len(levels))
+	for name := range levels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// swapAll 替换所有日志的输出，未配置的使用 default，返回需要关闭的旧输出
+func swapAll(writers map[string]zerolog.LevelWriter, newClosers []io.Closer, withs []WithContext) (old []io.Closer, fallbacks []string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	old, closers = closers, newClosers
+	currWiths = withs
+	initialized = true
+
+	if _, has := writers[DefaultName]; !has {
+		writers[DefaultName] = stderrWriter()
+	}
+
+	for name, w := range writers {
+		e, has := registry[name]
+		if !has {
+			e = &entry{w: newSwapWriter(w)}
+			registry[name] = e
+		} else {
+			e.w.swap(w)
+		}
+		e.build(name, withs, false)
+		delete(warned, name)
+	}
+
+	def := registry[DefaultName]
+	for name, e := range registry {
+		if _, has := writers[name]; has {
+			continue
+		}
+		e.w.swap(def.w)
+		e.build(name, withs, true)
+
+		if !warned[name] {
+			warned[name] = true
+			fallbacks = append(fallbacks, name)
+		}
+	}
+
+	return old, fallbacks
+}
+
+func warnFallback(name string) {
+	logger := Get()
+	logger.Warn().Str("logger", name).Msg("logger not configured, fallback to default")
+}
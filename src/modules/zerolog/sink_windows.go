@@ -0,0 +1,27 @@
+package zerolog
+
+import (
+	"errors"
+
+	"github.com/gocommon/zerolog"
+)
+
+// NewSyslogWriter windows 不支持
+func NewSyslogWriter(network, addr, facility, tag string) (zerolog.LevelWriter, error) {
+	return nil, errors.New("zerolog: syslog is not supported on windows")
+}
+
+// JournalWriter windows 不支持
+type JournalWriter struct {
+	zerolog.LevelWriter
+}
+
+// NewJournalWriter windows 不支持
+func NewJournalWriter(socket, tag string) (*JournalWriter, error) {
+	return nil, errors.New("zerolog: journald is not supported on windows")
+}
+
+// Close io.Closer
+func (w *JournalWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,125 @@
+package zerolog
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SmtpOptions 邮件日志配置
+type SmtpOptions struct {
+	Host      string // host:port
+	User      string
+	Passwd    string
+	From      string // 默认 User
+	Receivers []string
+	Subject   string
+
+	Interval   time.Duration // 汇总发送间隔，默认 1 分钟
+	MaxPerHour int           // 每小时最多发送邮件数，默认 10，超过时累积到下一次
+	Retries    int           // 发送失败重试次数，默认 3，小于 0 不重试
+	RetryWait  time.Duration // 重试间隔，按次数递增，默认 1 秒
+	QueueSize  int           // 队列及每封邮件最多条目数，默认 1000，超过时丢弃并计数
+
+	// SendMail 发送方法，默认 smtp.SendMail，测试时可替换
+	SendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// SmtpWriter 异步汇总发送的邮件日志
+// Write 只入队不阻塞，DedupeFields 相同的日志合并计数，每 Interval 发送一封汇总邮件
+type SmtpWriter struct {
+	*batchWriter
+	opt SmtpOptions
+}
+
+// NewSmtpWriter 创建邮件日志并启动发送协程
+func NewSmtpWriter(opt SmtpOptions) *SmtpWriter {
+	if len(opt.From) == 0 {
+		opt.From = opt.User
+	}
+	if opt.Interval <= 0 {
+		opt.Interval = time.Minute
+	}
+	if opt.MaxPerHour <= 0 {
+		opt.MaxPerHour = 10
+	}
+	if opt.Retries < 0 {
+		opt.Retries = 0
+	} else if opt.Retries == 0 {
+		opt.Retries = 3
+	}
+	if opt.RetryWait <= 0 {
+		opt.RetryWait = time.Second
+	}
+	if opt.QueueSize <= 0 {
+		opt.QueueSize = 1000
+	}
+	if opt.SendMail == nil {
+		opt.SendMail = smtp.SendMail
+	}
+
+	w := &SmtpWriter{opt: opt}
+	w.batchWriter = newBatchWriter("smtp", batchOptions{
+		Interval:  opt.Interval,
+		Limit:     opt.MaxPerHour,
+		Per:       time.Hour,
+		Retries:   opt.Retries,
+		RetryWait: opt.RetryWait,
+		QueueSize: opt.QueueSize,
+	}, w.send)
+
+	return w
+}
+
+func (w *SmtpWriter) message(entries []*Entry, dropped uint64) []byte {
+	total := 0
+	for _, e := range entries {
+		total += e.Count
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("To: " + strings.Join(w.opt.Receivers, ", ") + "\r\n")
+	buf.WriteString("From: " + w.opt.From + "\r\n")
+	buf.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	subject := fmt.Sprintf("%s (%d events, %d unique)", w.opt.Subject, total, len(entries))
+	buf.WriteString("Subject: " + mime.QEncoding.Encode("UTF-8", subject) + "\r\n")
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	buf.WriteString(strings.Replace(digestText(entries, dropped), "\n", "\r\n", -1))
+
+	return buf.Bytes()
+}
+
+func (w *SmtpWriter) send(entries []*Entry, dropped uint64) error {
+	var auth smtp.Auth
+	if len(w.opt.User) > 0 {
+		host := w.opt.Host
+		if i := strings.LastIndex(host, ":"); i >= 0 {
+			host = host[:i]
+		}
+		auth = smtp.PlainAuth("", w.opt.User, w.opt.Passwd, host)
+	}
+
+	return w.opt.SendMail(w.opt.Host, auth, w.opt.From, w.opt.Receivers, w.message(entries, dropped))
+}
+
+// digestText 每条一行，重复的带次数和时间范围
+func digestText(entries []*Entry, dropped uint64) string {
+	var buf bytes.Buffer
+	for _, e := range entries {
+		if e.Count > 1 {
+			buf.WriteString(fmt.Sprintf("[x%d, %s ~ %s] ", e.Count, e.First.Format("15:04:05"), e.Last.Format("15:04:05")))
+		}
+		buf.WriteString(e.Text())
+		buf.WriteString("\n")
+	}
+
+	if dropped > 0 {
+		buf.WriteString(fmt.Sprintf("\n%d events dropped\n", dropped))
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,194 @@
+package zerolog
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSMTP 只实现 SendMail 用到的命令，前 fails 次 DATA 返回 451
+type fakeSMTP struct {
+	ln net.Listener
+
+	mu       sync.Mutex
+	fails    int
+	attempts int
+	mails    []string
+}
+
+func newFakeSMTP(t *testing.T, fails int) *fakeSMTP {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &fakeSMTP{ln: ln, fails: fails}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+
+	return s
+}
+
+func (s *fakeSMTP) serve(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		switch cmd := strings.ToUpper(strings.Fields(line + " x")[0]); cmd {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+		case "MAIL", "RCPT", "RSET", "NOOP":
+			reply("250 OK")
+		case "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+
+			s.mu.Lock()
+			s.attempts++
+			fail := s.attempts <= s.fails
+			if !fail {
+				s.mails = append(s.mails, data.String())
+			}
+			s.mu.Unlock()
+
+			if fail {
+				reply("451 try again")
+			} else {
+				reply("250 queued")
+			}
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func (s *fakeSMTP) result() (int, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.attempts, append([]string(nil), s.mails...)
+}
+
+func newTestSmtpWriter(s *fakeSMTP, retries int) *SmtpWriter {
+	return NewSmtpWriter(SmtpOptions{
+		Host:      s.ln.Addr().String(),
+		From:      "app@example.com",
+		Receivers: []string{"ops@example.com"},
+		Subject:   "错误日志",
+		Interval:  time.Hour,
+		Retries:   retries,
+		RetryWait: time.Millisecond,
+	})
+}
+
+func TestSmtpWriterBatchAndDrainOnClose(t *testing.T) {
+	s := newFakeSMTP(t, 0)
+	defer s.ln.Close()
+
+	w := newTestSmtpWriter(s, 0)
+	w.Write([]byte(`{"level":"error","time":"2026-10-18T10:00:00Z","request_id":"a","message":"db down"}` + "\n"))
+	w.Write([]byte(`{"level":"error","time":"2026-10-18T10:00:01Z","request_id":"b","message":"db down"}` + "\n"))
+	w.Write([]byte(`{"level":"error","time":"2026-10-18T10:00:02Z","request_id":"c","message":"timeout"}` + "\n"))
+
+	// Interval 未到，Close 时发送队列中剩余的日志
+	w.Close()
+
+	attempts, mails := s.result()
+	if attempts != 1 || len(mails) != 1 {
+		t.Fatalf("attempts %d, mails %d", attempts, len(mails))
+	}
+
+	mail := mails[0]
+	for _, want := range []string{
+		"Subject: =?UTF-8?q?",
+		"Date: ",
+		"[x2, ",
+		`"message":"timeout"`,
+	} {
+		if !strings.Contains(mail, want) {
+			t.Errorf("mail missing %q:\n%s", want, mail)
+		}
+	}
+	if strings.Contains(mail, "错误日志") {
+		t.Errorf("subject not encoded:\n%s", mail)
+	}
+}
+
+func TestSmtpWriterRetry(t *testing.T) {
+	s := newFakeSMTP(t, 2)
+	defer s.ln.Close()
+
+	w := newTestSmtpWriter(s, 2)
+	w.Write([]byte(`{"level":"error","message":"db down"}` + "\n"))
+	w.Close()
+
+	attempts, mails := s.result()
+	if attempts != 3 || len(mails) != 1 {
+		t.Fatalf("attempts %d, mails %d", attempts, len(mails))
+	}
+}
+
+func TestSmtpWriterNoRetry(t *testing.T) {
+	s := newFakeSMTP(t, 1)
+	defer s.ln.Close()
+
+	w := newTestSmtpWriter(s, -1)
+	w.Write([]byte(`{"level":"error","message":"db down"}` + "\n"))
+	w.Close()
+
+	attempts, mails := s.result()
+	if attempts != 1 || len(mails) != 0 {
+		t.Fatalf("attempts %d, mails %d", attempts, len(mails))
+	}
+}
+
+func TestSmtpWriterReceiversAndDedupePerRequestFields(t *testing.T) {
+	s := newFakeSMTP(t, 0)
+	defer s.ln.Close()
+
+	w := newTestSmtpWriter(s, 0)
+	w.opt.Receivers = []string{"ops@example.com", "dev@example.com"}
+	w.Write([]byte(`{"level":"error","remote_ip":"192.0.2.1","path":"/a","message":"db down"}` + "\n"))
+	w.Write([]byte(`{"level":"error","remote_ip":"192.0.2.2","path":"/b","message":"db down"}` + "\n"))
+	w.Close()
+
+	_, mails := s.result()
+	if len(mails) != 1 {
+		t.Fatalf("mails %d", len(mails))
+	}
+	for _, want := range []string{"To: ops@example.com, dev@example.com\r\n", "[x2, "} {
+		if !strings.Contains(mails[0], want) {
+			t.Errorf("mail missing %q:\n%s", want, mails[0])
+		}
+	}
+}
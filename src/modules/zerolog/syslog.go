@@ -0,0 +1,67 @@
+//go:build !windows
+// +build !windows
+
+package zerolog
+
+import (
+	"fmt"
+	"log/syslog"
+	"strings"
+
+	"github.com/gocommon/zerolog"
+)
+
+// Facilities syslog facility 名称
+var Facilities = map[string]syslog.Priority{
+	"kern":     syslog.LOG_KERN,
+	"user":     syslog.LOG_USER,
+	"mail":     syslog.LOG_MAIL,
+	"daemon":   syslog.LOG_DAEMON,
+	"auth":     syslog.LOG_AUTH,
+	"syslog":   syslog.LOG_SYSLOG,
+	"lpr":      syslog.LOG_LPR,
+	"news":     syslog.LOG_NEWS,
+	"uucp":     syslog.LOG_UUCP,
+	"cron":     syslog.LOG_CRON,
+	"authpriv": syslog.LOG_AUTHPRIV,
+	"ftp":      syslog.LOG_FTP,
+	"local0":   syslog.LOG_LOCAL0,
+	"local1":   syslog.LOG_LOCAL1,
+	"local2":   syslog.LOG_LOCAL2,
+	"local3":   syslog.LOG_LOCAL3,
+	"local4":   syslog.LOG_LOCAL4,
+	"local5":   syslog.LOG_LOCAL5,
+	"local6":   syslog.LOG_LOCAL6,
+	"local7":   syslog.LOG_LOCAL7,
+}
+
+// NewSyslogWriter 连接 syslog，network 为空时使用本机 unix socket，否则为 udp/tcp 远程地址
+// facility 默认 user，tag 默认程序名
+func NewSyslogWriter(network, addr, facility, tag string) (zerolog.LevelWriter, error) {
+	p := syslog.LOG_USER
+	if len(facility) > 0 {
+		f, ok := Facilities[strings.ToLower(facility)]
+		if !ok {
+			return nil, fmt.Errorf("zerolog: unknown syslog facility %q", facility)
+		}
+		p = f
+	}
+
+	w, err := syslog.Dial(network, addr, p|syslog.LOG_INFO, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	return &syslogLevelWriter{LevelWriter: zerolog.SyslogLevelWriter(w), w: w}, nil
+}
+
+// syslogLevelWriter 按级别写入 syslog，可关闭
+type syslogLevelWriter struct {
+	zerolog.LevelWriter
+	w *syslog.Writer
+}
+
+// Close io.Closer
+func (w *syslogLevelWriter) Close() error {
+	return w.w.Close()
+}
@@ -0,0 +1,152 @@
+package zerolog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// WebhookTemplates 预置请求体模板，模板数据为 WebhookData
+var WebhookTemplates = map[string]string{
+	// 钉钉、企业微信文本消息
+	"dingtalk": `{"msgtype":"text","text":{"content":{{json .Text}}}}`,
+	"wecom":    `{"msgtype":"text","text":{"content":{{json .Text}}}}`,
+	// Slack 兼容
+	"slack": `{"text":{{json .Text}}}`,
+	// 原始 json
+	"json": `{"title":{{json .Title}},"count":{{.Count}},"dropped":{{.Dropped}},"entries":[{{range $i, $e := .Entries}}{{if $i}},{{end}}{"count":{{$e.Count}},"log":{{$e.JSON}}}{{end}}]}`,
+}
+
+// WebhookData 请求体模板数据
+type WebhookData struct {
+	Title   string
+	Count   int    // 日志条数，包含重复
+	Dropped uint64 // 队列满丢弃的条数
+	Entries []*Entry
+	Text    string // 标题和每条日志的文本
+}
+
+// WebhookOptions webhook 日志配置
+type WebhookOptions struct {
+	URL   string
+	Title string
+
+	// Template 预置模板名称(dingtalk, wecom, slack, json)或 text/template 内容，默认 json
+	// 模板中可用 json 函数转义字符串，如 {{json .Text}}
+	Template    string
+	ContentType string // 默认 application/json
+	Timeout     time.Duration
+
+	Interval     time.Duration // 汇总发送间隔，默认 10 秒
+	MaxPerMinute int           // 每分钟最多请求数，默认 10，超过时累积到下一次
+	Retries      int           // 失败重试次数，默认 3，小于 0 不重试
+	RetryWait    time.Duration // 重试间隔，按次数递增，默认 1 秒
+	QueueSize    int           // 队列及每次最多条目数，默认 100，超过时丢弃并计数
+
+	// Client 默认使用 Timeout 的 http.Client
+	Client *http.Client
+}
+
+// WebhookWriter 异步汇总推送到 webhook
+type WebhookWriter struct {
+	*batchWriter
+	opt  WebhookOptions
+	tmpl *template.Template
+}
+
+// NewWebhookWriter 创建 webhook 日志并启动发送协程，模板错误时返回 error
+func NewWebhookWriter(opt WebhookOptions) (*WebhookWriter, error) {
+	if len(opt.URL) == 0 {
+		return nil, fmt.Errorf("zerolog: webhook writer requires url")
+	}
+	if len(opt.Template) == 0 {
+		opt.Template = "json"
+	}
+	if t, ok := WebhookTemplates[strings.ToLower(opt.Template)]; ok {
+		opt.Template = t
+	}
+	if len(opt.ContentType) == 0 {
+		opt.ContentType = "application/json"
+	}
+	if opt.Timeout <= 0 {
+		opt.Timeout = 5 * time.Second
+	}
+	if opt.Interval <= 0 {
+		opt.Interval = 10 * time.Second
+	}
+	if opt.MaxPerMinute <= 0 {
+		opt.MaxPerMinute = 10
+	}
+	if opt.Retries < 0 {
+		opt.Retries = 0
+	} else if opt.Retries == 0 {
+		opt.Retries = 3
+	}
+	if opt.RetryWait <= 0 {
+		opt.RetryWait = time.Second
+	}
+	if opt.QueueSize <= 0 {
+		opt.QueueSize = 100
+	}
+	if opt.Client == nil {
+		opt.Client = &http.Client{Timeout: opt.Timeout}
+	}
+
+	tmpl, err := template.New("webhook").Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}).Parse(opt.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &WebhookWriter{opt: opt, tmpl: tmpl}
+	w.batchWriter = newBatchWriter("webhook", batchOptions{
+		Interval:  opt.Interval,
+		Limit:     opt.MaxPerMinute,
+		Per:       time.Minute,
+		Retries:   opt.Retries,
+		RetryWait: opt.RetryWait,
+		QueueSize: opt.QueueSize,
+	}, w.send)
+
+	return w, nil
+}
+
+func (w *WebhookWriter) send(entries []*Entry, dropped uint64) error {
+	data := WebhookData{
+		Title:   w.opt.Title,
+		Dropped: dropped,
+		Entries: entries,
+	}
+	for _, e := range entries {
+		data.Count += e.Count
+	}
+	data.Text = fmt.Sprintf("%s (%d events, %d unique)\n%s", w.opt.Title, data.Count, len(entries), digestText(entries, dropped))
+
+	var body bytes.Buffer
+	if err := w.tmpl.Execute(&body, data); err != nil {
+		return err
+	}
+
+	res, err := w.opt.Client.Post(w.opt.URL, w.opt.ContentType, &body)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(ioutil.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("webhook status %d", res.StatusCode)
+	}
+
+	return nil
+}
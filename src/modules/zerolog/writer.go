@@ -0,0 +1,44 @@
+package zerolog
+
+import (
+	"io"
+	"sync/atomic"
+
+	"github.com/gocommon/zerolog"
+)
+
+// levelWriter 按级别过滤的 io.Writer，级别可以在运行时修改
+type levelWriter struct {
+	io.Writer
+	level uint32
+}
+
+// NewLevelWriter 只写入不低于 level 的日志
+func NewLevelWriter(w io.Writer, level zerolog.Level) zerolog.LevelWriter {
+	return newLevelWriter(w, level)
+}
+
+func newLevelWriter(w io.Writer, level zerolog.Level) *levelWriter {
+	return &levelWriter{Writer: w, level: uint32(level)}
+}
+
+// Level 当前级别
+func (w *levelWriter) Level() zerolog.Level {
+	return zerolog.Level(atomic.LoadUint32(&w.level))
+}
+
+// SetLevel 修改级别
+func (w *levelWriter) SetLevel(level zerolog.Level) {
+	atomic.StoreUint32(&w.level, uint32(level))
+}
+
+// WriteLevel zerolog.LevelWriter，被包装的是 LevelWriter 时传递级别，如 syslog
+func (w *levelWriter) WriteLevel(l zerolog.Level, p []byte) (int, error) {
+	if l < w.Level() {
+		return len(p), nil
+	}
+	if lw, ok := w.Writer.(zerolog.LevelWriter); ok {
+		return lw.WriteLevel(l, p)
+	}
+	return w.Write(p)
+}
@@ -5,9 +5,8 @@ import (
 
 	"io"
 
-	"fmt"
+	"time"
 
-	"github.com/gocommon/rotatefile"
 	"github.com/gocommon/zerolog"
 	"github.com/gocommon/zerolog/op"
 )
@@ -19,19 +18,87 @@ type Option struct {
 	Level  string
 
 	// model file
-	FileName string // 文件名
-	// LogRotate    bool   // 分割文件
-	// MaxLines     int    // 最大行数
-	// MaxSizeShift int    // 最大文件大小 1 << MaxSizeShift
-	// DailyRotate  bool   // 每天分割文件
-	// MaxDays      int    // 分割文件保留天数
+	FileName     string // 文件名
+	LogRotate    *bool  // 分割文件，默认 true
+	MaxLines     int    `validate:"min=0"`        // 最大行数，默认 1000000
+	MaxSizeShift int    `validate:"min=0,max=62"` // 最大文件大小 1 << MaxSizeShift，默认 28(256MB)
+	DailyRotate  *bool  // 每天分割文件，默认 true
+	MaxDays      int    // 分割文件保留天数，默认 7，小于 0 不按天数删除
+
+	MaxBackups        int    `validate:"min=0"`        // 最多保留分割文件数量，0 不限制
+	MaxTotalSizeShift int    `validate:"min=0,max=62"` // 分割文件最多保留 1 << MaxTotalSizeShift 字节，0 不限制
+	Compress          bool   // gzip 压缩分割文件
+	RotateTimeFormat  string // 分割文件名时间格式，默认 2006-01-02
 
 	// model smtp
 	User      string
-	Passwd    string
+	Passwd    string `secret:"true"`
 	Host      string
 	Receivers []string // default "[]"
 	Subject   string
+	From      string // 发件人，默认 User
+
+	// model webhook，标题使用 Subject
+	URL               string `secret:"true"` // 通常带 access_token
+	Template          string // 预置模板 dingtalk, wecom, slack, json 或 text/template 内容，默认 json
+	MaxPostsPerMinute int    `validate:"min=0"` // 每分钟最多请求数，默认 10
+
+	// model syslog, journald
+	Network  string // syslog: 空为本机 unix socket，udp/tcp 为远程
+	Addr     string // syslog: 远程地址 host:port；journald: socket 路径，默认 /run/systemd/journal/socket
+	Facility string // syslog: 默认 user，如 local0
+	Tag      string // 默认程序名
+
+	// model smtp, webhook
+	DigestSeconds   int `validate:"min=0"`  // 汇总发送间隔(秒)，smtp 默认 60，webhook 默认 10
+	MaxMailsPerHour int `validate:"min=0"`  // 每小时最多发送邮件数，默认 10
+	Retries         int `validate:"min=-1"` // 发送失败重试次数，默认 3，-1 不重试
+	QueueSize       int `validate:"min=0"`  // 队列大小，smtp 默认 1000，webhook 默认 100
+}
+
+// 文件分割默认值
+const (
+	DefaultMaxLines     = 1000000
+	DefaultMaxSizeShift = 28
+	DefaultMaxDays      = 7
+)
+
+// RotateOptions 文件分割配置，未设置的项使用默认值
+func (opt Option) RotateOptions() FileOptions {
+	fopt := FileOptions{
+		Filename:   opt.FileName,
+		Rotate:     true,
+		MaxLines:   opt.MaxLines,
+		MaxSize:    1 << DefaultMaxSizeShift,
+		Daily:      true,
+		MaxDays:    opt.MaxDays,
+		MaxBackups: opt.MaxBackups,
+		Compress:   opt.Compress,
+		TimeFormat: opt.RotateTimeFormat,
+	}
+
+	if opt.LogRotate != nil {
+		fopt.Rotate = *opt.LogRotate
+	}
+	if fopt.MaxLines <= 0 {
+		fopt.MaxLines = DefaultMaxLines
+	}
+	if opt.MaxSizeShift > 0 {
+		fopt.MaxSize = 1 << uint(opt.MaxSizeShift)
+	}
+	if opt.DailyRotate != nil {
+		fopt.Daily = *opt.DailyRotate
+	}
+	if fopt.MaxDays == 0 {
+		fopt.MaxDays = DefaultMaxDays
+	} else if fopt.MaxDays < 0 {
+		fopt.MaxDays = 0
+	}
+	if opt.MaxTotalSizeShift > 0 {
+		fopt.MaxTotalSize = 1 << uint(opt.MaxTotalSizeShift)
+	}
+
+	return fopt
 }
 
 // Levels Levels
@@ -44,6 +111,9 @@ var Levels = map[string]zerolog.Level{
 	"panic": zerolog.PanicLevel,
 }
 
+// Modes 支持的输出方式
+var Modes = []string{"console", "file", "smtp", "webhook", "syslog", "journald"}
+
 // LevelByString LevelByString
 func LevelByString(str string) zerolog.Level {
 	str = strings.ToLower(str)
@@ -55,9 +125,6 @@ func LevelByString(str string) zerolog.Level {
 	return zerolog.DebugLevel
 }
 
-// Loggers Loggers
-var Loggers = map[string]zerolog.Logger{}
-
 // WithContext WithContext
 type WithContext func(zerolog.Context) zerolog.Context
 
@@ -75,12 +142,28 @@ func Version(ver string) WithContext {
 	}
 }
 
-// InitLog InitLog
+// InitLog 按配置创建日志输出，可以重复调用，已获取的日志写入新的输出，旧的输出被关闭
 func InitLog(confs map[string]map[string]Option, withs ...WithContext) {
+	var (
+		newClosers []io.Closer
+		all        = make(map[string]zerolog.LevelWriter, len(confs))
+		allLevels  = make(map[string]*loggerLevels, len(confs))
+	)
+
+	// 创建输出失败时关闭已创建的，继续使用原来的输出
+	defer func() {
+		if r := recover(); r != nil {
+			for i := range newClosers {
+				newClosers[i].Close()
+			}
+			panic(r)
+		}
+	}()
 
 	for name := range confs {
 
 		writers := make([]io.Writer, 0, len(confs[name]))
+		sinks := make(map[string]*levelWriter, len(confs[name]))
 
 		for k := range confs[name] {
 			opt := confs[name][k]
@@ -88,46 +171,112 @@ func InitLog(confs map[string]map[string]Option, withs ...WithContext) {
 				continue
 			}
 
+			var w io.Writer
+
 			switch strings.ToLower(opt.Mode) {
 			case "console":
-
-				writers = append(writers, op.NewConsole(LevelByString(opt.Level)))
+				// 级别由 levelWriter 控制
+				w = op.NewConsole(zerolog.DebugLevel)
 
 			case "file":
-				fd, err := rotatefile.NewWriter(rotatefile.Options{Filename: opt.FileName})
+				fd, err := NewFileWriter(opt.RotateOptions())
 				if err != nil {
 					panic(err)
 				}
 
-				writers = append(writers, op.NewFileWriter(fd, LevelByString(opt.Level)))
+				newClosers = append(newClosers, fd)
+				w = fd
 
 			case "smtp":
-				writers = append(writers, op.NewSmtpWriter(opt.User, opt.Passwd, opt.Host, opt.Subject, opt.Receivers, LevelByString(opt.Level)))
-			}
-		}
+				sw := NewSmtpWriter(SmtpOptions{
+					Host:       opt.Host,
+					User:       opt.User,
+					Passwd:     opt.Passwd,
+					From:       opt.From,
+					Receivers:  opt.Receivers,
+					Subject:    opt.Subject,
+					Interval:   time.Duration(opt.DigestSeconds) * time.Second,
+					MaxPerHour: opt.MaxMailsPerHour,
+					Retries:    opt.Retries,
+					QueueSize:  opt.QueueSize,
+				})
+
+				newClosers = append(newClosers, sw)
+				w = sw
+
+			case "webhook":
+				ww, err := NewWebhookWriter(WebhookOptions{
+					URL:          opt.URL,
+					Title:        opt.Subject,
+					Template:     opt.Template,
+					Interval:     time.Duration(opt.DigestSeconds) * time.Second,
+					MaxPerMinute: opt.MaxPostsPerMinute,
+					Retries:      opt.Retries,
+					QueueSize:    opt.QueueSize,
+				})
+				if err != nil {
+					panic(err)
+				}
 
-		c := zerolog.New(zerolog.MultiLevelWriter(writers...)).With()
+				newClosers = append(newClosers, ww)
+				w = ww
 
-		for i := range withs {
-			c = withs[i](c)
+			case "syslog":
+				sw, err := NewSyslogWriter(opt.Network, opt.Addr, opt.Facility, opt.Tag)
+				if err != nil {
+					panic(err)
+				}
+
+				if c, ok := sw.(io.Closer); ok {
+					newClosers = append(newClosers, c)
+				}
+				w = sw
+
+			case "journald":
+				jw, err := NewJournalWriter(opt.Addr, opt.Tag)
+				if err != nil {
+					panic(err)
+				}
+
+				newClosers = append(newClosers, jw)
+				w = jw
+
+			default:
+				continue
+			}
+
+			lw := newLevelWriter(w, LevelByString(opt.Level))
+			sinks[k] = lw
+			writers = append(writers, lw)
 		}
 
-		Loggers[name] = c.Logger()
+		// 日志级别，运行时通过 SetLevel 修改
+		level := newLevelWriter(zerolog.MultiLevelWriter(writers...), zerolog.DebugLevel)
+		allLevels[name] = newLoggerLevels(level, sinks)
+		all[name] = level
 	}
 
-}
+	old, fallbacks := swapAll(all, newClosers, withs)
+	replaceLevels(allLevels)
 
-// Get Get
-func Get(name ...string) zerolog.Logger {
-	cname := "default"
-	if len(name) > 0 {
-		cname = name[0]
+	for i := range old {
+		old[i].Close()
 	}
-	if logger, has := Loggers[cname]; has {
-		return logger
+	for i := range fallbacks {
+		warnFallback(fallbacks[i])
 	}
+}
 
-	panic(fmt.Sprintf("log.Get miss name %s, forget to Initlog ?", name))
+// Close 关闭所有输出，文件写入磁盘，邮件发送队列中剩余的日志
+func Close() {
+	registryMu.Lock()
+	old := closers
+	closers = nil
+	registryMu.Unlock()
+
+	for i := range old {
+		old[i].Close()
+	}
 }
 
 // With With
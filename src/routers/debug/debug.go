@@ -2,6 +2,7 @@ package debug
 
 import (
 	"routers"
+	"setting"
 
 	h "handlers/debug"
 
@@ -12,8 +13,13 @@ import (
 var _ routers.RouterRegister = debugRouters
 
 func debugRouters(e *echo.Echo) {
-	r := e.Group("/debug")
+	r := e.Group("/debug", guard(setting.Conf.Debug))
 	r.GET("/version", h.Version)
+	r.GET("/config", h.Config)
+
+	// 日志级别
+	r.GET("/loggers", h.Loggers)
+	r.PUT("/loggers/:name", h.SetLoggerLevel)
 
 }
 
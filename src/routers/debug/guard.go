@@ -0,0 +1,50 @@
+package debug
+
+import (
+	"crypto/subtle"
+	"net"
+	"setting"
+
+	"modules/errors"
+
+	"github.com/labstack/echo"
+)
+
+// HeaderDebugToken 访问 /debug 接口的 token 请求头
+const HeaderDebugToken = "X-Debug-Token"
+
+// guard /debug 接口访问控制，见 setting.DebugOption
+func guard(opt setting.DebugOption) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !opt.AllowRemote && !isLocal(c) {
+				return errors.ErrForbidden
+			}
+
+			if len(opt.Token) > 0 {
+				token := c.Request().Header.Get(HeaderDebugToken)
+				if subtle.ConstantTimeCompare([]byte(token), []byte(opt.Token)) != 1 {
+					return errors.ErrUnauthorized
+				}
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// isLocal 本机直接访问，不使用可以伪造的 X-Forwarded-For
+// 经本机反向代理的请求带有 X-Forwarded-For 或 X-Real-IP，不算本机
+func isLocal(c echo.Context) bool {
+	req := c.Request()
+	if len(req.Header.Get(echo.HeaderXForwardedFor)) > 0 || len(req.Header.Get(echo.HeaderXRealIP)) > 0 {
+		return false
+	}
+
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,58 @@
+package debug
+
+import (
+	"net/http/httptest"
+	"setting"
+	"testing"
+
+	"modules/errors"
+
+	"github.com/labstack/echo"
+)
+
+func TestGuard(t *testing.T) {
+	cases := []struct {
+		name   string
+		opt    setting.DebugOption
+		remote string
+		header map[string]string
+		want   error
+	}{
+		{"local", setting.DebugOption{}, "127.0.0.1:1234", nil, nil},
+		{"local ipv6", setting.DebugOption{}, "[::1]:1234", nil, nil},
+		{"remote", setting.DebugOption{}, "192.0.2.1:1234", nil, errors.ErrForbidden},
+		{"proxied", setting.DebugOption{}, "127.0.0.1:1234", map[string]string{echo.HeaderXForwardedFor: "192.0.2.1"}, errors.ErrForbidden},
+		{"local without token", setting.DebugOption{Token: "t"}, "127.0.0.1:1234", nil, errors.ErrUnauthorized},
+		{"remote with token", setting.DebugOption{AllowRemote: true, Token: "t"}, "192.0.2.1:1234", map[string]string{HeaderDebugToken: "t"}, nil},
+		{"remote wrong token", setting.DebugOption{AllowRemote: true, Token: "t"}, "192.0.2.1:1234", map[string]string{HeaderDebugToken: "x"}, errors.ErrUnauthorized},
+	}
+
+	for _, tc := range cases {
+		req := httptest.NewRequest(echo.GET, "/debug/loggers", nil)
+		req.RemoteAddr = tc.remote
+		for k, v := range tc.header {
+			req.Header.Set(k, v)
+		}
+		c := echo.New().NewContext(req, httptest.NewRecorder())
+
+		err := guard(tc.opt)(func(echo.Context) error { return nil })(c)
+		if err != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestConfigGuarded(t *testing.T) {
+	e := echo.New()
+	var got error
+	e.HTTPErrorHandler = func(err error, c echo.Context) { got = err }
+	debugRouters(e)
+
+	req := httptest.NewRequest(echo.GET, "/debug/config", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got != errors.ErrForbidden {
+		t.Fatalf("got %v, want ErrForbidden", got)
+	}
+}
@@ -0,0 +1,154 @@
+package setting
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// Decoder 配置文件解析为 map
+type Decoder func(data []byte) (map[string]interface{}, error)
+
+// Decoders 按扩展名选择解析方式
+var Decoders = map[string]Decoder{
+	".toml": decodeTOML,
+	".yaml": decodeYAML,
+	".yml":  decodeYAML,
+	".json": decodeJSON,
+}
+
+// RegisterDecoder 注册其它格式，ext 如 .ini
+func RegisterDecoder(ext string, d Decoder) {
+	Decoders[strings.ToLower(ext)] = d
+}
+
+func decodeTOML(data []byte) (map[string]interface{}, error) {
+	tree := map[string]interface{}{}
+	_, err := toml.Decode(string(data), &tree)
+	return tree, err
+}
+
+func decodeYAML(data []byte) (map[string]interface{}, error) {
+	tree := map[interface{}]interface{}{}
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+	return normalize(tree).(map[string]interface{}), nil
+}
+
+func decodeJSON(data []byte) (map[string]interface{}, error) {
+	tree := map[string]interface{}{}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	err := d.Decode(&tree)
+	return tree, err
+}
+
+// decodeFile 读取配置文件，替换其中的 ${ENV}，返回没有对应配置项的 key
+func decodeFile(path string) (map[string]interface{}, []string, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	d, has := Decoders[ext]
+	if !has {
+		return nil, nil, fmt.Errorf("unsupported config file %s", path)
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	data := os.ExpandEnv(string(contents))
+
+	tree, err := d([]byte(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %s", path, err)
+	}
+	return tree, unknownKeys(ext, data, tree), nil
+}
+
+// unknownKeys 拼错或已删除的配置项
+// toml 使用 MetaData.Undecoded，类型不符解析失败时及其它格式按配置项路径查找
+func unknownKeys(ext, data string, tree map[string]interface{}) []string {
+	keys := []string{}
+
+	if ext == ".toml" {
+		if md, err := toml.Decode(data, newConfig()); err == nil {
+			for _, key := range md.Undecoded() {
+				// 未知的表只记录其中的 key
+				if md.Type(key...) != "Hash" {
+					keys = append(keys, key.String())
+				}
+			}
+			return keys
+		}
+	}
+
+	conf := reflect.ValueOf(newConfig()).Elem()
+	for _, key := range leafKeys(tree, nil, nil) {
+		if _, ok := canonicalPath(conf, key); !ok {
+			keys = append(keys, strings.Join(key, "."))
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// normalize yaml 的 map[interface{}]interface{} 转为 map[string]interface{}
+func normalize(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			m[fmt.Sprint(k)] = normalize(item)
+		}
+		return m
+	case []interface{}:
+		for i := range val {
+			val[i] = normalize(val[i])
+		}
+	}
+	return v
+}
+
+// mergeTree 后面的配置覆盖前面的，子表逐项合并，key 不区分大小写
+func mergeTree(dst, src map[string]interface{}) {
+	for k, v := range src {
+		key := k
+		for dk := range dst {
+			if strings.EqualFold(dk, k) {
+				key = dk
+				break
+			}
+		}
+
+		sub, isMap := v.(map[string]interface{})
+		dsub, dIsMap := dst[key].(map[string]interface{})
+		if isMap && dIsMap {
+			mergeTree(dsub, sub)
+			continue
+		}
+		dst[key] = v
+	}
+}
+
+// leafKeys 配置文件中出现的配置项路径
+func leafKeys(tree map[string]interface{}, path []string, keys [][]string) [][]string {
+	for k, v := range tree {
+		p := append(append([]string{}, path...), k)
+		if sub, ok := v.(map[string]interface{}); ok {
+			keys = leafKeys(sub, p, keys)
+			continue
+		}
+		keys = append(keys, p)
+	}
+	return keys
+}
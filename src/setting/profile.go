@@ -0,0 +1,91 @@
+package setting
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ProfileEnv 选择环境的环境变量，-profile 参数优先
+const ProfileEnv = "APP_ENV"
+
+// DefaultProfile 没有指定环境时使用，与未区分环境前的行为一致
+var DefaultProfile = "prod"
+
+// profiles 各环境的默认值，在配置文件之前生效
+var profiles = map[string]func(c *Config){}
+
+// RegisterProfile 注册环境及其默认值
+func RegisterProfile(name string, defaults func(c *Config)) {
+	profiles[strings.ToLower(name)] = defaults
+}
+
+// Profiles 已注册的环境
+func Profiles() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	RegisterProfile("dev", func(c *Config) {
+		c.Echo.Debug = true
+	})
+	RegisterProfile("test", func(c *Config) {})
+	RegisterProfile("staging", func(c *Config) {})
+	RegisterProfile("prod", func(c *Config) {})
+}
+
+// resolveProfile 环境：-profile 参数 > APP_ENV > DefaultProfile，返回环境及来源
+func resolveProfile(flagValue string) (profile, from string) {
+	if len(flagValue) > 0 {
+		return strings.ToLower(flagValue), SourceFlag + ":-profile"
+	}
+	if env := os.Getenv(ProfileEnv); len(env) > 0 {
+		return strings.ToLower(env), SourceEnv + ":" + ProfileEnv
+	}
+	return DefaultProfile, SourceDefault
+}
+
+// applyProfile 设置环境默认值
+func applyProfile(c *Config, profile string, sources map[string]string) error {
+	defaults, has := profiles[profile]
+	if !has {
+		return fmt.Errorf("unknown profile %q, want one of %s", profile, strings.Join(Profiles(), ", "))
+	}
+	before := map[string]interface{}{}
+	walkLeaves(reflect.ValueOf(c).Elem(), nil, false, func(key string, v reflect.Value, _ bool) {
+		before[key] = v.Interface()
+	})
+
+	c.Profile = profile
+	defaults(c)
+
+	walkLeaves(reflect.ValueOf(c).Elem(), nil, false, func(key string, v reflect.Value, _ bool) {
+		if old, has := before[key]; !has || !reflect.DeepEqual(old, v.Interface()) {
+			sources[key] = SourceProfile + ":" + profile
+		}
+	})
+	return nil
+}
+
+// profilePaths 每个配置文件之后加上存在的环境配置，如 app.toml, app.prod.toml
+func profilePaths(paths []string, profile string) []string {
+	out := make([]string, 0, len(paths)*2)
+	for _, path := range paths {
+		out = append(out, path)
+
+		ext := filepath.Ext(path)
+		overlay := strings.TrimSuffix(path, ext) + "." + profile + ext
+		if _, err := os.Stat(overlay); err == nil {
+			out = append(out, overlay)
+		}
+	}
+	return out
+}
@@ -0,0 +1,252 @@
+package setting
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"modules/zerolog"
+)
+
+// DefaultWatchSeconds 检查配置文件修改时间的默认间隔
+const DefaultWatchSeconds = 5
+
+// state 配置及各项来源，重新加载时整体替换
+type state struct {
+	conf    *Config
+	sources map[string]string
+}
+
+var (
+	current atomic.Value // state
+
+	// reloadMu 串行化重新加载
+	reloadMu    sync.Mutex
+	lastArgs    loadArgs
+	subscribers []subscription
+)
+
+func init() {
+	current.Store(state{conf: Conf, sources: map[string]string{}})
+}
+
+func currentState() state {
+	return current.Load().(state)
+}
+
+// Get 当前配置，重新加载后返回新的配置，不要修改返回值
+func Get() *Config {
+	return currentState().conf
+}
+
+// Subscriber 配置变化时重新应用
+// 返回错误或 panic 时不替换配置，已应用的订阅按相反顺序以 (new, old) 调用回滚
+// 失败时需要关闭自己已打开的资源，不替换正在使用的
+type Subscriber func(old, new *Config) error
+
+type subscription struct {
+	sections []string
+	fn       Subscriber
+}
+
+// Subscribe 订阅配置项变化，section 为配置项路径前缀
+// 如 "ZeroLogs"、"Echo.CrosAllowOrigins"、"Echo.AccessLog"(匹配 Echo.AccessLog*)
+// 变化的配置项没有订阅时需要重启才能生效
+func Subscribe(fn Subscriber, sections ...string) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	subscribers = append(subscribers, subscription{sections: sections, fn: fn})
+}
+
+// ReloadResult 重新加载结果
+type ReloadResult struct {
+	Changed []string `json:"changed"`          // 变化的配置项
+	Applied []string `json:"applied"`          // 已重新应用的订阅
+	Restart []string `json:"restart"`          // 需要重启才能生效的配置项
+	Errors  []string `json:"errors,omitempty"` // 订阅重新应用失败，此时已回滚
+}
+
+// Reload 使用启动时的参数重新加载配置，校验通过后先通知订阅，全部应用成功后再替换当前配置
+// 加载、校验或订阅失败时保持当前配置，订阅失败时返回 ConfigErrors
+func Reload() (ReloadResult, error) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	result := ReloadResult{Changed: []string{}, Applied: []string{}, Restart: []string{}}
+
+	c, sources, err := loadConfig(lastArgs)
+	if err != nil {
+		ev := zerolog.Error()
+		if errs, ok := err.(ConfigErrors); ok {
+			ev = ev.Strs("errors", errs)
+		} else {
+			ev = ev.Err(err)
+		}
+		ev.Msg("config reload failed, keep current config")
+		return result, err
+	}
+
+	old := Get()
+	// 版本号由程序设置
+	c.Version = old.Version
+
+	result.Changed = changedKeys(old, c)
+	if len(result.Changed) == 0 {
+		zerolog.Info().Msg("config reloaded, nothing changed")
+		return result, nil
+	}
+
+	covered := map[string]bool{}
+	applied := []subscription{}
+	for _, sub := range subscribers {
+		matched := false
+		for _, key := range result.Changed {
+			if matchSections(key, sub.sections) {
+				matched = true
+				covered[key] = true
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		name := strings.Join(sub.sections, ",")
+		if err := callSubscriber(sub.fn, old, c); err != nil {
+			result.Errors = append(result.Errors, name+": "+err.Error())
+			break
+		}
+		applied = append(applied, sub)
+		result.Applied = append(result.Applied, name)
+	}
+
+	if len(result.Errors) > 0 {
+		// 回滚已应用的订阅，保持当前配置
+		for i := len(applied) - 1; i >= 0; i-- {
+			if err := callSubscriber(applied[i].fn, c, old); err != nil {
+				result.Errors = append(result.Errors, "rollback "+strings.Join(applied[i].sections, ",")+": "+err.Error())
+			}
+		}
+		result.Applied = []string{}
+
+		zerolog.Error().Strs("errors", result.Errors).
+			Strs("changed", result.Changed).
+			Msg("config reload failed, keep current config")
+		return result, ConfigErrors(result.Errors)
+	}
+
+	current.Store(state{conf: c, sources: sources})
+
+	for _, key := range result.Changed {
+		if !covered[key] {
+			result.Restart = append(result.Restart, key)
+		}
+	}
+
+	zerolog.Info().Strs("changed", result.Changed).
+		Strs("applied", result.Applied).
+		Strs("restart", result.Restart).
+		Msg("config reloaded")
+
+	return result, nil
+}
+
+// callSubscriber 订阅中的 panic 转为错误，如 InitLog 创建输出失败
+func callSubscriber(fn Subscriber, old, new *Config) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return fn(old, new)
+}
+
+func matchSections(key string, sections []string) bool {
+	for _, section := range sections {
+		if strings.HasPrefix(key, section) {
+			return true
+		}
+	}
+	return false
+}
+
+// changedKeys 比较两份配置，返回变化的配置项
+func changedKeys(old, new *Config) []string {
+	values := map[string]interface{}{}
+	walkLeaves(reflect.ValueOf(old).Elem(), nil, false, func(key string, v reflect.Value, _ bool) {
+		values[key] = v.Interface()
+	})
+
+	changed := []string{}
+	walkLeaves(reflect.ValueOf(new).Elem(), nil, false, func(key string, v reflect.Value, _ bool) {
+		ov, has := values[key]
+		if !has || !reflect.DeepEqual(ov, v.Interface()) {
+			changed = append(changed, key)
+		}
+		delete(values, key)
+	})
+
+	// 删除的配置项，如 ZeroLogs 中去掉的输出
+	for key := range values {
+		changed = append(changed, key)
+	}
+
+	sort.Strings(changed)
+	return changed
+}
+
+// Watch 每 interval 检查配置文件修改时间，变化时重新加载，返回停止函数
+func Watch(interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		interval = DefaultWatchSeconds * time.Second
+	}
+
+	done := make(chan struct{})
+	go func() {
+		last := modTimes()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			mt := modTimes()
+			if reflect.DeepEqual(mt, last) {
+				continue
+			}
+			last = mt
+			Reload()
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+// modTimes 配置文件及环境配置的修改时间，新增或删除环境配置也视为变化
+func modTimes() map[string]time.Time {
+	reloadMu.Lock()
+	args := lastArgs
+	reloadMu.Unlock()
+
+	profile, _ := resolveProfile(args.profile)
+
+	mt := map[string]time.Time{}
+	for _, path := range profilePaths(args.paths, profile) {
+		if fi, err := os.Stat(path); err == nil {
+			mt[path] = fi.ModTime()
+		}
+	}
+	return mt
+}
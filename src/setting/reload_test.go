@@ -0,0 +1,94 @@
+package setting
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeConf(t *testing.T, dir, content string) string {
+	path := filepath.Join(dir, "app.toml")
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "setting")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestReloadAppliesBeforePublish(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	defer func() { subscribers = nil }()
+
+	path := writeConf(t, dir, "[Echo]\nBodyDump = false\n")
+	if err := InitConf("", []string{path}); err != nil {
+		t.Fatal(err)
+	}
+
+	var seen []bool
+	Subscribe(func(old, new *Config) error {
+		// 订阅应用时还没有替换
+		seen = append(seen, Get() == old, new.Echo.BodyDump)
+		return nil
+	}, "Echo.BodyDump")
+
+	writeConf(t, dir, "[Echo]\nBodyDump = true\n")
+	result, err := Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(seen) != 2 || !seen[0] || !seen[1] {
+		t.Fatalf("seen %v", seen)
+	}
+	if !Get().Echo.BodyDump || len(result.Applied) != 1 {
+		t.Fatalf("not published: %+v", result)
+	}
+}
+
+func TestReloadRollbackOnSubscriberFailure(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	defer func() { subscribers = nil }()
+
+	path := writeConf(t, dir, "[Echo]\nBodyDump = false\nCrosEnable = false\n")
+	if err := InitConf("", []string{path}); err != nil {
+		t.Fatal(err)
+	}
+	before := Get()
+
+	applied := []bool{}
+	Subscribe(func(old, new *Config) error {
+		applied = append(applied, new.Echo.BodyDump)
+		return nil
+	}, "Echo.BodyDump")
+	Subscribe(func(old, new *Config) error {
+		panic(errors.New("cannot open log file"))
+	}, "Echo.Cros")
+
+	writeConf(t, dir, "[Echo]\nBodyDump = true\nCrosEnable = true\n")
+	result, err := Reload()
+	if _, ok := err.(ConfigErrors); !ok {
+		t.Fatalf("want ConfigErrors, got %v", err)
+	}
+
+	if Get() != before {
+		t.Fatal("config published after subscriber failure")
+	}
+	// 应用后回滚为原来的值
+	if len(applied) != 2 || !applied[0] || applied[1] {
+		t.Fatalf("applied %v", applied)
+	}
+	if len(result.Applied) != 0 || len(result.Errors) != 1 {
+		t.Fatalf("result %+v", result)
+	}
+}
@@ -1,20 +1,45 @@
 package setting
 
 import (
-	"io/ioutil"
-	"os"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
 
+	"modules/pagination"
+	"modules/responser"
 	"modules/zerolog"
-
-	"github.com/BurntSushi/toml"
 )
 
 // Config Config
 type Config struct {
 	Version string
+	Profile string // 当前环境 dev/test/staging/prod，由 -profile 或 APP_ENV 指定，配置文件中的值无效
 	Echo    EchoService
 
-	ZeroLogs map[string]map[string]zerolog.Option
+	ZeroLogs map[string]map[string]zerolog.Option `validate:"dive,dive"`
+	Redact   zerolog.RedactOption
+
+	Responser  responser.Option
+	Pagination pagination.Option
+
+	Reload ReloadOption
+	Debug  DebugOption
+}
+
+// DebugOption /debug 接口访问控制，修改后需要重启
+type DebugOption struct {
+	// AllowRemote 允许非本机访问，此时必须设置 Token
+	// 默认只允许本机直接访问，经反向代理(带 X-Forwarded-For/X-Real-IP)的请求不算本机
+	AllowRemote bool
+	// Token 不为空时请求头 X-Debug-Token 必须相同，本机访问也需要
+	Token string
+}
+
+// ReloadOption 重新加载配置，SIGHUP 总是重新加载
+type ReloadOption struct {
+	Watch        bool // 定时检查配置文件修改时间，变化时重新加载
+	WatchSeconds int  `validate:"min=0"` // 检查间隔(秒)，默认 5
 }
 
 // EchoService EchoService
@@ -22,19 +47,35 @@ type EchoService struct {
 	Debug      bool
 	HideBanner bool // 是否隐藏echo banner日志输出
 
-	Listen string
-
-	AccessLog         bool // 是否显示访问日志
-	AccessLogFile     bool
-	AccessLogFilePath string
+	Listen string `validate:"required,listen"`
+
+	AccessLog           bool   // 是否显示访问日志
+	AccessLogger        string // 访问日志 ZeroLogs 名称，默认 access
+	AccessLogSampleRate int    `validate:"min=0"` // 2xx/3xx 每 N 条记录一条
+	AccessLogSlowMillis int    `validate:"min=0"` // 慢请求阈值(毫秒)，总是记录
+	AccessLogHeaders    bool   // 记录请求头，不在 Redact.Headers 中的值脱敏
+	BodyDump            bool   // debug 级别记录脱敏后的请求体和返回值
+	// ZeroLogs 中没有 AccessLogger 时使用
+	AccessLogFile         bool
+	AccessLogFilePath     string
+	AccessLogRotate       *bool // 分割文件，默认 true
+	AccessLogMaxLines     int   `validate:"min=0"`        // 最大行数
+	AccessLogMaxSizeShift int   `validate:"min=0,max=62"` // 最大文件大小 1 << AccessLogMaxSizeShift
+	AccessLogDailyRotate  *bool // 每天分割文件，默认 true
+	AccessLogMaxDays      int   // 分割文件保留天数
+	// 分割文件保留数量、总大小 1 << AccessLogMaxTotalSizeShift，0 不限制
+	AccessLogMaxBackups        int    `validate:"min=0"`
+	AccessLogMaxTotalSizeShift int    `validate:"min=0,max=62"`
+	AccessLogCompress          bool   // gzip 压缩分割文件
+	AccessLogRotateTimeFormat  string // 分割文件名时间格式，默认 2006-01-02
 
 	CrosEnable       bool
-	CrosAllowOrigins []string
+	CrosAllowOrigins []string `validate:"dive,required"`
 
 	GzipEnable bool
 }
 
-// Conf Conf配置内容
+// Conf 启动时的配置内容，运行中重新加载后使用 Get() 获取当前配置
 var Conf = newConfig()
 
 func newConfig() *Config {
@@ -42,11 +83,16 @@ func newConfig() *Config {
 	return &Config{
 		Echo: EchoService{
 			// Debug:      true,
-			HideBanner: true,
-			Listen:     ":8899",
-			AccessLog:  true,
-			GzipEnable: true,
+			HideBanner:   true,
+			Listen:       ":8899",
+			AccessLog:    true,
+			AccessLogger: "access",
+			GzipEnable:   true,
 		},
+		Responser: responser.Option{
+			SuccessMessage: "ok",
+		},
+		Pagination: pagination.DefaultOption,
 		ZeroLogs: map[string]map[string]zerolog.Option{
 			"default": {
 				"console": {
@@ -59,25 +105,149 @@ func newConfig() *Config {
 	}
 }
 
-// InitConf InitConf 初始化配置
-func InitConf(confPath string) (err error) {
+// InitConf 初始化配置，按扩展名解析 .toml/.yaml/.yml/.json
+// 多个配置文件按顺序合并，后面的覆盖前面的，每个文件之后合并对应的环境配置，如 app.toml, app.dev.toml
+// 优先级：newConfig 默认值 < 环境默认值 < 配置文件 < 环境变量(APP_ECHO_LISTEN) < 命令行 -set Echo.Listen=:9000
+// profile 为 -profile 参数，为空时使用 APP_ENV 或 DefaultProfile
+func InitConf(profile string, confPaths []string, sets ...string) (err error) {
+	args := loadArgs{profile: profile, paths: confPaths, sets: sets}
 
-	contents, err := replaceEnvsFile(confPath)
+	c, sources, err := loadConfig(args)
 	if err != nil {
 		return err
 	}
 
-	if _, err = toml.Decode(contents, &Conf); err != nil {
-		return err
-	}
+	reloadMu.Lock()
+	lastArgs = args
+	reloadMu.Unlock()
+
+	Conf = c
+	current.Store(state{conf: c, sources: sources})
 
 	return nil
 }
 
-func replaceEnvsFile(path string) (string, error) {
-	contents, err := ioutil.ReadFile(path)
+// loadArgs 加载配置的参数，重新加载时使用相同的参数
+type loadArgs struct {
+	profile string
+	paths   []string
+	sets    []string
+}
+
+// loadConfig 从默认值开始加载一份新的配置
+// 未知的配置项、类型不符及校验失败的问题全部返回，见 ConfigErrors
+func loadConfig(args loadArgs) (*Config, map[string]string, error) {
+	c := newConfig()
+	sources := map[string]string{}
+	errs := ConfigErrors{}
+
+	profile, from := resolveProfile(args.profile)
+
+	// 未知的环境与其它问题一起返回
+	if err := applyProfile(c, profile, sources); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	merged := map[string]interface{}{}
+	for _, path := range profilePaths(args.paths, profile) {
+		tree, unknown, err := decodeFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, key := range unknown {
+			errs = append(errs, fmt.Sprintf("%s: unknown key %s", filepath.Base(path), key))
+		}
+		mergeTree(merged, tree)
+		markSources(c, leafKeys(tree, nil, nil), SourceFile+":"+filepath.Base(path), sources)
+	}
+
+	// 统一转为 json 解码到配置，字段名不区分大小写
+	data, err := json.Marshal(merged)
 	if err != nil {
-		return "", err
+		return nil, nil, err
+	}
+	if err = json.Unmarshal(data, c); err != nil {
+		// 类型不符时其它配置项仍然解码，Unmarshal 只返回第一个，逐项解码找出所有的
+		if _, ok := err.(*json.UnmarshalTypeError); !ok {
+			return nil, nil, err
+		}
+		errs = append(errs, typeErrors(merged)...)
+	}
+
+	errs = append(errs, applyEnv(c, sources)...)
+	errs = append(errs, applySets(c, args.sets, sources)...)
+
+	// 环境只能由 -profile 或 APP_ENV 指定
+	c.Profile = profile
+	sources["Profile"] = from
+
+	c.accessLogs()
+
+	errs = append(errs, validate(c)...)
+	if len(errs) > 0 {
+		return nil, nil, errs
+	}
+
+	return c, sources, nil
+}
+
+// typeErrors 逐个配置项解码，返回所有类型不符的配置项
+func typeErrors(merged map[string]interface{}) ConfigErrors {
+	errs := ConfigErrors{}
+	for _, path := range leafKeys(merged, nil, nil) {
+		leaf := map[string]interface{}{}
+		dst, src := leaf, merged
+		for _, k := range path[:len(path)-1] {
+			sub := map[string]interface{}{}
+			dst[k], dst = sub, sub
+			src = src[k].(map[string]interface{})
+		}
+		last := path[len(path)-1]
+		dst[last] = src[last]
+
+		data, err := json.Marshal(leaf)
+		if err != nil {
+			continue
+		}
+		if te, ok := json.Unmarshal(data, newConfig()).(*json.UnmarshalTypeError); ok {
+			errs = append(errs, fmt.Sprintf("%s: cannot use %s as %s", te.Field, te.Value, te.Type))
+		}
+	}
+
+	sort.Strings(errs)
+	return errs
+}
+
+// accessLogs ZeroLogs 中没有访问日志配置时，按 AccessLogFile 输出到文件或控制台
+func (c *Config) accessLogs() {
+	if len(c.Echo.AccessLogger) == 0 {
+		c.Echo.AccessLogger = "access"
+	}
+	if _, has := c.ZeroLogs[c.Echo.AccessLogger]; has {
+		return
+	}
+
+	opt := zerolog.Option{
+		Enable: true,
+		Mode:   "console",
+		Level:  "info",
+	}
+	if c.Echo.AccessLogFile {
+		opt.Mode = "file"
+		opt.FileName = c.Echo.AccessLogFilePath
+		opt.LogRotate = c.Echo.AccessLogRotate
+		opt.MaxLines = c.Echo.AccessLogMaxLines
+		opt.MaxSizeShift = c.Echo.AccessLogMaxSizeShift
+		opt.DailyRotate = c.Echo.AccessLogDailyRotate
+		opt.MaxDays = c.Echo.AccessLogMaxDays
+		opt.MaxBackups = c.Echo.AccessLogMaxBackups
+		opt.MaxTotalSizeShift = c.Echo.AccessLogMaxTotalSizeShift
+		opt.Compress = c.Echo.AccessLogCompress
+		opt.RotateTimeFormat = c.Echo.AccessLogRotateTimeFormat
+	}
+
+	if c.ZeroLogs == nil {
+		c.ZeroLogs = map[string]map[string]zerolog.Option{}
 	}
-	return os.ExpandEnv(string(contents)), nil
+	c.ZeroLogs[c.Echo.AccessLogger] = map[string]zerolog.Option{opt.Mode: opt}
 }
@@ -0,0 +1,169 @@
+package setting
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func sourceOf(key string) Source {
+	for _, s := range Sources() {
+		if s.Key == key {
+			return s
+		}
+	}
+	return Source{}
+}
+
+func TestLoadLayering(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	path := writeConf(t, dir, "[Echo]\nListen = \":9001\"\nAccessLogSampleRate = 2\nAccessLogSlowMillis = 100\n")
+	if err := ioutil.WriteFile(filepath.Join(dir, "app.dev.toml"), []byte("[Echo]\nAccessLogSampleRate = 3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("APP_ECHO_ACCESSLOGSLOWMILLIS", "200")
+	t.Setenv("APP_ECHO_LISTEN", ":9002")
+
+	if err := InitConf("dev", []string{path}, "Echo.Listen=:9003"); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		key   string
+		value interface{}
+		from  string
+	}{
+		{"Profile", "dev", SourceFlag + ":-profile"},
+		{"Echo.Debug", true, SourceProfile + ":dev"},
+		{"Echo.HideBanner", true, SourceDefault},
+		{"Echo.AccessLogSampleRate", 3, SourceFile + ":app.dev.toml"},
+		{"Echo.AccessLogSlowMillis", 200, SourceEnv + ":APP_ECHO_ACCESSLOGSLOWMILLIS"},
+		{"Echo.Listen", ":9003", SourceFlag + ":-set"},
+	}
+	for _, tc := range cases {
+		s := sourceOf(tc.key)
+		if s.Value != tc.value || s.From != tc.from {
+			t.Errorf("%s: got %v from %s, want %v from %s", tc.key, s.Value, s.From, tc.value, tc.from)
+		}
+	}
+}
+
+func TestLoadReportsAllTypeErrors(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	path := writeConf(t, dir, "[Echo]\nAccessLogSampleRate = \"often\"\nAccessLogSlowMillis = \"slow\"\nListen = \":9001\"\n")
+	err := InitConf("", []string{path})
+
+	errs, ok := err.(ConfigErrors)
+	if !ok {
+		t.Fatalf("want ConfigErrors, got %v", err)
+	}
+	msg := errs.Error()
+	for _, want := range []string{"Echo.AccessLogSampleRate", "Echo.AccessLogSlowMillis"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("missing %s in %s", want, msg)
+		}
+	}
+}
+
+func TestLoadUnknownProfileWithOtherErrors(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	path := writeConf(t, dir, "[Echo]\nListen = \"nope\"\nUnknownKey = 1\n")
+	err := InitConf("qa", []string{path})
+
+	errs, ok := err.(ConfigErrors)
+	if !ok {
+		t.Fatalf("want ConfigErrors, got %v", err)
+	}
+	msg := errs.Error()
+	for _, want := range []string{`unknown profile "qa"`, "unknown key Echo.UnknownKey", "Listen"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("missing %q in %s", want, msg)
+		}
+	}
+}
+
+func TestValidateSinks(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	path := writeConf(t, dir, `
+[ZeroLogs.default.console]
+Enable = true
+Mode   = "consol"
+Level  = "verbose"
+
+[ZeroLogs.default.smtp]
+Enable  = true
+Mode    = "smtp"
+Level   = "error"
+Retries = -2
+`)
+	err := InitConf("", []string{path})
+
+	errs, ok := err.(ConfigErrors)
+	if !ok {
+		t.Fatalf("want ConfigErrors, got %v", err)
+	}
+	if len(errs) < 3 {
+		t.Fatalf("want at least 3 problems, got %s", errs)
+	}
+	msg := errs.Error()
+	for _, want := range []string{"consol", "verbose", "Retries"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("missing %q in %s", want, msg)
+		}
+	}
+}
+
+func TestValidateDebugRemoteNeedsToken(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	path := writeConf(t, dir, "[Debug]\nAllowRemote = true\n")
+	err := InitConf("", []string{path})
+
+	errs, ok := err.(ConfigErrors)
+	if !ok || len(errs) != 1 || !strings.Contains(errs[0], "Debug.Token") {
+		t.Fatalf("want Debug.Token problem, got %v", err)
+	}
+}
+
+func TestAccessLogFileRotateOptions(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	path := writeConf(t, dir, `
+[Echo]
+AccessLogFile              = true
+AccessLogFilePath          = "`+filepath.ToSlash(filepath.Join(dir, "access.log"))+`"
+AccessLogMaxBackups        = 5
+AccessLogMaxTotalSizeShift = 30
+AccessLogCompress          = true
+AccessLogRotateTimeFormat  = "20060102"
+`)
+	if err := InitConf("", []string{path}); err != nil {
+		t.Fatal(err)
+	}
+
+	opt := Conf.ZeroLogs["access"]["file"]
+	if opt.MaxBackups != 5 || opt.MaxTotalSizeShift != 30 || !opt.Compress || opt.RotateTimeFormat != "20060102" {
+		t.Fatalf("got %+v", opt)
+	}
+
+	writeConf(t, dir, "[Echo]\nAccessLogFile = true\nAccessLogFilePath = \"access.log\"\nAccessLogMaxBackups = -1\nAccessLogRotateTimeFormat = \"daily\"\n")
+	msg := fmt.Sprint(InitConf("", []string{path}))
+	for _, want := range []string{"Echo.AccessLogMaxBackups", "Echo.AccessLogRotateTimeFormat"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("missing %q in %s", want, msg)
+		}
+	}
+}
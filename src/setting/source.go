@@ -0,0 +1,399 @@
+package setting
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"modules/zerolog"
+)
+
+// EnvPrefix 环境变量前缀，如 APP_ECHO_LISTEN 覆盖 Echo.Listen
+var EnvPrefix = "APP"
+
+// 配置来源
+const (
+	SourceDefault = "default"
+	SourceProfile = "profile"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+// Source 配置项的生效值及来源
+type Source struct {
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"`
+	From  string      `json:"from"` // default, profile:dev, file:app.toml, env:APP_ECHO_LISTEN, flag:-set
+}
+
+// SetFlags -set Echo.Listen=:9000 命令行参数，可重复
+type SetFlags []string
+
+// String flag.Value
+func (s *SetFlags) String() string {
+	return strings.Join(*s, ",")
+}
+
+// Set flag.Value
+func (s *SetFlags) Set(v string) error {
+	if !strings.Contains(v, "=") {
+		return fmt.Errorf("invalid -set %q, want Key.Path=value", v)
+	}
+	*s = append(*s, v)
+	return nil
+}
+
+// Sources 当前配置所有配置项的生效值及来源，敏感值脱敏
+func Sources() []Source {
+	st := currentState()
+
+	list := []Source{}
+	walkLeaves(reflect.ValueOf(st.conf).Elem(), nil, false, func(key string, v reflect.Value, secret bool) {
+		from, has := st.sources[key]
+		if !has {
+			from = SourceDefault
+		}
+
+		var value interface{}
+		if v.IsValid() && v.CanInterface() {
+			value = v.Interface()
+		}
+		if secret && v.IsValid() && !v.IsZero() {
+			value = zerolog.Redacted
+		}
+
+		list = append(list, Source{Key: key, Value: value, From: from})
+	})
+	return list
+}
+
+// walkLeaves 遍历配置项，map 按 key 排序
+func walkLeaves(v reflect.Value, path []string, secret bool, fn func(key string, v reflect.Value, secret bool)) {
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if len(f.PkgPath) > 0 {
+				continue
+			}
+			s := secret || f.Tag.Get(zerolog.SecretTag) == "true" || zerolog.IsSecretKey(f.Name)
+			walkLeaves(v.Field(i), append(path, f.Name), s, fn)
+		}
+		return
+
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, k := range keys {
+			walkLeaves(v.MapIndex(k), append(path, k.String()), secret || zerolog.IsSecretKey(k.String()), fn)
+		}
+		return
+	}
+
+	fn(strings.Join(path, "."), v, secret)
+}
+
+// applyEnv 按环境变量覆盖配置，无法对应配置项的变量忽略
+func applyEnv(c *Config, sources map[string]string) ConfigErrors {
+	errs := ConfigErrors{}
+
+	prefix := strings.ToUpper(EnvPrefix) + "_"
+
+	envs := os.Environ()
+	sort.Strings(envs)
+
+	for _, kv := range envs {
+		i := strings.Index(kv, "=")
+		if i < 0 || !strings.HasPrefix(kv[:i], prefix) {
+			continue
+		}
+		name, value := kv[:i], kv[i+1:]
+
+		path, ok := envPath(reflect.ValueOf(c).Elem(), strings.Split(name[len(prefix):], "_"))
+		if !ok {
+			continue
+		}
+
+		key, err := setPath(reflect.ValueOf(c).Elem(), path, value)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("env %s: %s", name, err))
+			continue
+		}
+		sources[key] = SourceEnv + ":" + name
+	}
+
+	return errs
+}
+
+// applySets 按 -set 参数覆盖配置
+func applySets(c *Config, sets []string, sources map[string]string) ConfigErrors {
+	errs := ConfigErrors{}
+	for _, set := range sets {
+		i := strings.Index(set, "=")
+		if i < 0 {
+			errs = append(errs, fmt.Sprintf("invalid -set %q, want Key.Path=value", set))
+			continue
+		}
+
+		key, err := setPath(reflect.ValueOf(c).Elem(), strings.Split(set[:i], "."), set[i+1:])
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("-set %s: %s", set, err))
+			continue
+		}
+		sources[key] = SourceFlag + ":-set"
+	}
+
+	return errs
+}
+
+// markSources 记录配置文件中出现的配置项
+func markSources(c *Config, keys [][]string, from string, sources map[string]string) {
+	for _, key := range keys {
+		if path, ok := canonicalPath(reflect.ValueOf(c).Elem(), key); ok {
+			sources[strings.Join(path, ".")] = from
+		}
+	}
+}
+
+// envPath 环境变量名转配置路径
+// 结构体字段名可以用 _ 分隔，如 ACCESS_LOG_FILE 或 ACCESSLOGFILE
+// map 优先匹配已有的 key，如 ZEROLOGS_DEFAULT_FILE_LEVEL 对应 ZeroLogs.default.file.Level
+func envPath(v reflect.Value, segs []string) ([]string, bool) {
+	if len(segs) == 0 {
+		return nil, isLeaf(v.Type())
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.Type().Elem().Kind() == reflect.Struct {
+			return envPath(reflect.New(v.Type().Elem()).Elem(), segs)
+		}
+
+	case reflect.Struct:
+		t := v.Type()
+		for n := 1; n <= len(segs); n++ {
+			name := strings.Join(segs[:n], "")
+			for i := 0; i < t.NumField(); i++ {
+				f := t.Field(i)
+				if len(f.PkgPath) > 0 || !strings.EqualFold(f.Name, name) {
+					continue
+				}
+				if rest, ok := envPath(v.Field(i), segs[n:]); ok {
+					return append([]string{f.Name}, rest...), true
+				}
+			}
+		}
+
+	case reflect.Map:
+		for n := len(segs); n >= 1; n-- {
+			name := strings.Join(segs[:n], "_")
+			elem := reflect.New(v.Type().Elem()).Elem()
+			key, exists := mapKeyFold(v, name)
+			if exists {
+				elem = v.MapIndex(reflect.ValueOf(key))
+			} else if n > 1 {
+				continue
+			} else {
+				key = strings.ToLower(name)
+			}
+			if rest, ok := envPath(elem, segs[n:]); ok {
+				return append([]string{key}, rest...), true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// canonicalPath 配置文件中的路径转为字段名的大小写
+func canonicalPath(v reflect.Value, path []string) ([]string, bool) {
+	out := make([]string, 0, len(path))
+	for _, p := range path {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v = reflect.New(v.Type().Elem()).Elem()
+			} else {
+				v = v.Elem()
+			}
+		}
+
+		switch v.Kind() {
+		case reflect.Struct:
+			f, ok := fieldFold(v.Type(), p)
+			if !ok {
+				return nil, false
+			}
+			out = append(out, f.Name)
+			v = v.FieldByIndex(f.Index)
+
+		case reflect.Map:
+			key, _ := mapKeyFold(v, p)
+			if len(key) == 0 {
+				key = p
+			}
+			out = append(out, key)
+			mv := v.MapIndex(reflect.ValueOf(key))
+			if !mv.IsValid() {
+				mv = reflect.New(v.Type().Elem()).Elem()
+			}
+			v = mv
+
+		default:
+			return nil, false
+		}
+	}
+
+	return out, isLeaf(v.Type())
+}
+
+// setPath 按路径设置配置项，返回规范的 key
+func setPath(v reflect.Value, path []string, raw string) (string, error) {
+	if len(path) == 0 {
+		return "", setValue(v, raw)
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.Type().Elem().Kind() == reflect.Struct {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			return setPath(v.Elem(), path, raw)
+		}
+
+	case reflect.Struct:
+		f, ok := fieldFold(v.Type(), path[0])
+		if !ok {
+			return "", fmt.Errorf("unknown key %s", path[0])
+		}
+		key, err := setPath(v.FieldByIndex(f.Index), path[1:], raw)
+		return join(f.Name, key), err
+
+	case reflect.Map:
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+
+		name, exists := mapKeyFold(v, path[0])
+		if !exists {
+			name = path[0]
+		}
+		k := reflect.ValueOf(name)
+
+		// map 的值不可寻址，修改副本后写回
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if exists {
+			elem.Set(v.MapIndex(k))
+		}
+		key, err := setPath(elem, path[1:], raw)
+		if err != nil {
+			return "", err
+		}
+		v.SetMapIndex(k, elem)
+		return join(name, key), nil
+	}
+
+	return "", fmt.Errorf("unknown key %s", strings.Join(path, "."))
+}
+
+// setValue 字符串转为配置项类型，切片用逗号分隔
+func setValue(v reflect.Value, raw string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+
+	case reflect.Ptr:
+		p := reflect.New(v.Type().Elem())
+		if err := setValue(p.Elem(), raw); err != nil {
+			return err
+		}
+		v.Set(p)
+
+	case reflect.Slice:
+		parts := []string{}
+		if raw = strings.TrimSpace(raw); len(raw) > 0 {
+			parts = strings.Split(raw, ",")
+		}
+		s := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setValue(s.Index(i), strings.TrimSpace(part)); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+func isLeaf(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() != reflect.Struct && t.Kind() != reflect.Map
+}
+
+func fieldFold(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if len(f.PkgPath) == 0 && strings.EqualFold(f.Name, name) {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func mapKeyFold(v reflect.Value, name string) (string, bool) {
+	if v.IsNil() {
+		return "", false
+	}
+	for _, k := range v.MapKeys() {
+		if strings.EqualFold(k.String(), name) {
+			return k.String(), true
+		}
+	}
+	return "", false
+}
+
+func join(name, key string) string {
+	if len(key) == 0 {
+		return name
+	}
+	return name + "." + key
+}
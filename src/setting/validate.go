@@ -0,0 +1,219 @@
+package setting
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"modules/validator"
+	"modules/zerolog"
+
+	v9 "gopkg.in/go-playground/validator.v9"
+)
+
+// ValidateLang 配置校验错误描述的语言
+var ValidateLang = "en"
+
+// ConfigErrors 配置的所有问题，加载时一次返回
+type ConfigErrors []string
+
+// Error error
+func (e ConfigErrors) Error() string {
+	return fmt.Sprintf("invalid config, %d problem(s):\n  %s", len(e), strings.Join(e, "\n  "))
+}
+
+func init() {
+	validator.RegisterRule("listen", isListen, validator.Messages{
+		"en": "{0} must be a listen address like :8899 or 127.0.0.1:8899",
+		"zh": "{0}必须是监听地址，如 :8899 或 127.0.0.1:8899",
+	})
+}
+
+func isListen(fl v9.FieldLevel) bool {
+	return checkListen(fl.Field().String()) == nil
+}
+
+// checkListen host:port，host 可以为空，port 为 0-65535
+func checkListen(addr string) error {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		return fmt.Errorf("invalid port %q", port)
+	}
+	return nil
+}
+
+// validate 按 validate tag 及日志输出的级别、方式、文件路径等校验配置
+func validate(c *Config) ConfigErrors {
+	errs := ConfigErrors{}
+
+	if err := validator.New().Validate(c); err != nil {
+		ve, ok := err.(v9.ValidationErrors)
+		if !ok {
+			return append(errs, err.Error())
+		}
+		for _, fe := range validator.FieldErrors(ve, validator.LangTranslator(ValidateLang)) {
+			errs = append(errs, configKey(fe.Field)+": "+fe.Message)
+		}
+	}
+
+	if c.Echo.AccessLogFile {
+		if err := checkTimeFormat(c.Echo.AccessLogRotateTimeFormat); err != nil {
+			errs = append(errs, "Echo.AccessLogRotateTimeFormat: "+err.Error())
+		}
+	}
+
+	if c.Debug.AllowRemote && len(c.Debug.Token) == 0 {
+		errs = append(errs, "Debug.Token: required when Debug.AllowRemote is true")
+	}
+
+	names := make([]string, 0, len(c.ZeroLogs))
+	for name := range c.ZeroLogs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		sinks := make([]string, 0, len(c.ZeroLogs[name]))
+		for sink := range c.ZeroLogs[name] {
+			sinks = append(sinks, sink)
+		}
+		sort.Strings(sinks)
+
+		for _, sink := range sinks {
+			errs = append(errs, validateSink("ZeroLogs."+name+"."+sink, c.ZeroLogs[name][sink])...)
+		}
+	}
+
+	return errs
+}
+
+// validateSink 启用的日志输出，级别及方式拼错时不再静默使用 debug 或跳过
+func validateSink(key string, opt zerolog.Option) ConfigErrors {
+	errs := ConfigErrors{}
+	if !opt.Enable {
+		return errs
+	}
+
+	mode := strings.ToLower(opt.Mode)
+	if !hasString(zerolog.Modes, mode) {
+		errs = append(errs, fmt.Sprintf("%s.Mode: unknown mode %q, want one of %s", key, opt.Mode, strings.Join(zerolog.Modes, ", ")))
+	}
+
+	if len(opt.Level) > 0 {
+		if _, err := zerolog.ParseLevel(opt.Level); err != nil {
+			errs = append(errs, fmt.Sprintf("%s.Level: unknown level %q, want one of debug, info, warn, error, fatal, panic", key, opt.Level))
+		}
+	}
+
+	required := func(field, value string) {
+		if len(value) == 0 {
+			errs = append(errs, fmt.Sprintf("%s.%s: required by mode %s", key, field, mode))
+		}
+	}
+
+	switch mode {
+	case "file":
+		required("FileName", opt.FileName)
+		if len(opt.FileName) > 0 {
+			if err := checkFilePath(opt.FileName); err != nil {
+				errs = append(errs, fmt.Sprintf("%s.FileName: %s", key, err))
+			}
+		}
+		if err := checkTimeFormat(opt.RotateTimeFormat); err != nil {
+			errs = append(errs, fmt.Sprintf("%s.RotateTimeFormat: %s", key, err))
+		}
+
+	case "smtp":
+		required("Host", opt.Host)
+		if len(opt.Receivers) == 0 {
+			errs = append(errs, fmt.Sprintf("%s.Receivers: required by mode %s", key, mode))
+		}
+
+	case "webhook":
+		required("URL", opt.URL)
+		if u, err := url.Parse(opt.URL); len(opt.URL) > 0 && (err != nil || (u.Scheme != "http" && u.Scheme != "https")) {
+			// URL 通常带 token，不输出原值
+			errs = append(errs, fmt.Sprintf("%s.URL: must be an http or https url", key))
+		}
+
+	case "syslog":
+		switch opt.Network {
+		case "":
+		case "udp", "tcp":
+			required("Addr", opt.Addr)
+		default:
+			errs = append(errs, fmt.Sprintf("%s.Network: unknown network %q, want empty, udp or tcp", key, opt.Network))
+		}
+	}
+
+	return errs
+}
+
+// checkFilePath 文件不是目录，所在目录存在或可以创建
+func checkFilePath(name string) error {
+	if fi, err := os.Stat(name); err == nil && fi.IsDir() {
+		return fmt.Errorf("%s is a directory", name)
+	}
+
+	for dir := filepath.Dir(name); ; {
+		fi, err := os.Stat(dir)
+		if err == nil {
+			if !fi.IsDir() {
+				return fmt.Errorf("%s is not a directory", dir)
+			}
+			return nil
+		}
+		if !os.IsNotExist(err) {
+			return err
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil
+		}
+		dir = parent
+	}
+}
+
+// checkTimeFormat 分割文件名时间格式，为空时使用默认值
+// 需要包含日期或时间，不能包含路径分隔符
+func checkTimeFormat(layout string) error {
+	if len(layout) == 0 {
+		return nil
+	}
+	if strings.ContainsAny(layout, `/\`) {
+		return fmt.Errorf("time format %q must not contain path separators", layout)
+	}
+
+	stamp := time.Now().Format(layout)
+	if stamp == layout {
+		return fmt.Errorf("time format %q has no date or time, like 2006-01-02", layout)
+	}
+	if _, err := time.Parse(layout, stamp); err != nil {
+		return fmt.Errorf("time format %q: %s", layout, err)
+	}
+	return nil
+}
+
+// configKey ZeroLogs[default][file].MaxLines 转为 ZeroLogs.default.file.MaxLines
+func configKey(field string) string {
+	return strings.NewReplacer("[", ".", "]", "").Replace(field)
+}
+
+func hasString(list []string, s string) bool {
+	for i := range list {
+		if list[i] == s {
+			return true
+		}
+	}
+	return false
+}